@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"pdf-extractor/internal/actions"
+
+	"github.com/spf13/cobra"
+)
+
+var CacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the extracted page text cache",
+	Long:  `The cache command manages the page text cached in --cache-dir by extract-index, extract and delete-pages`,
+}
+
+var CacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove all cached page text",
+	Long:  `The clear command removes every entry from the page text cache in --cache-dir`,
+	RunE:  clearCache,
+}
+
+func init() {
+	CacheCmd.AddCommand(CacheClearCmd)
+	rootCmd.AddCommand(CacheCmd)
+}
+func clearCache(cmd *cobra.Command, args []string) error {
+	var cmds []actions.Command
+	cmds = append(cmds, &actions.CacheClearSettings{
+		CacheDir: cacheDir,
+	})
+	invoker := actions.Invoker{
+		Command: cmds,
+	}
+	if err := invoker.ExecuteCommand(); err != nil {
+		return err
+	}
+	return nil
+}
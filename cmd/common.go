@@ -1,5 +1,16 @@
 package cmd
 
+import (
+	"fmt"
+	"pdf-extractor/internal/cache"
+	"pdf-extractor/internal/extractor"
+	"pdf-extractor/internal/services"
+	"pdf-extractor/internal/tools"
+	"pdf-extractor/internal/writer"
+
+	"github.com/spf13/cobra"
+)
+
 var (
 	file       string
 	outputPath string
@@ -9,4 +20,62 @@ var (
 	backupPath string
 	fromPage   int
 	toPage     int
+	textEngine string
+	pageWriter string
+	cacheDir   string
+	jobs       int
+	ocrMode    string
+	dryRun     bool
+	planFormat string
 )
+
+// newTextExtractor builds the text extractor selected by the global flags
+func newTextExtractor() (extractor.TextExtractor, error) {
+	ext, err := extractor.New(textEngine, jobs)
+	if err != nil {
+		return nil, err
+	}
+	if err := tools.Check(tools.Required(ext)); err != nil {
+		return nil, fmt.Errorf("--text-engine=%s: %v (or use --text-engine=%s)", textEngine, err, extractor.EngineNative)
+	}
+	ext, err = extractor.NewOCRExtractor(ext, ocrMode, jobs)
+	if err != nil {
+		return nil, err
+	}
+	if err := tools.Check(tools.Required(ext)); err != nil {
+		return nil, fmt.Errorf("--ocr=%s: %v (or use --ocr=%s)", ocrMode, err, extractor.OCRAuto)
+	}
+	if cacheDir != "" {
+		c := cache.New(cacheDir)
+		// A dry run must not write anything, not even to the cache
+		c.ReadOnly = dryRun
+		ext = cache.NewExtractor(c, ext)
+	}
+	return ext, nil
+}
+
+// addDryRunFlags adds --dry-run and --format to a command that writes or deletes files
+func addDryRunFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print what would be done without writing or deleting any file")
+	cmd.Flags().StringVar(&planFormat, "format", services.PlanTable, "Format of the --dry-run plan (table|json)")
+}
+
+// dryRunFormat returns the format of the plan to print, or "" to run the command for real
+func dryRunFormat() string {
+	if !dryRun {
+		return ""
+	}
+	return planFormat
+}
+
+// newPageWriter builds the page writer selected by the global flags
+func newPageWriter() (writer.PageWriter, error) {
+	pw, err := writer.New(pageWriter)
+	if err != nil {
+		return nil, err
+	}
+	if err := tools.Check(tools.Required(pw)); err != nil {
+		return nil, fmt.Errorf("--page-writer=%s: %v (or use --page-writer=%s)", pageWriter, err, writer.WriterPdfcpu)
+	}
+	return pw, nil
+}
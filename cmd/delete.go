@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"pdf-extractor/internal/actions"
+	"pdf-extractor/internal/services"
 
 	"github.com/spf13/cobra"
 )
@@ -16,7 +17,8 @@ var DeleteCmd = &cobra.Command{
 func init() {
 	DeleteCmd.Flags().StringVarP(&file, "file", "f", "", "Path to the PDF file")
 	DeleteCmd.Flags().StringVar(&backupPath, "backup-path", "./backup", "Path to save backup files")
-	DeletePagesCommand.Flags().BoolVar(&skipBackup, "no-backup", false, "Create a backup of the PDF file before deleting pages")
+	DeleteCmd.Flags().BoolVar(&skipBackup, "no-backup", false, "Skip creating a backup of the PDF file before deleting it")
+	addDryRunFlags(DeleteCmd)
 	DeleteCmd.MarkFlagRequired("file")
 	rootCmd.AddCommand(DeleteCmd)
 }
@@ -26,9 +28,11 @@ func deletePDF(cmd *cobra.Command, args []string) error {
 		File:       file,
 		BackupPath: backupPath,
 		BackupFlag: !skipBackup,
+		PlanFormat: dryRunFormat(),
 	})
 	invoker := actions.Invoker{
 		Command: cmds,
+		Quiet:   dryRunFormat() == services.PlanJSON,
 	}
 	if err := invoker.ExecuteCommand(); err != nil {
 		return err
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"pdf-extractor/internal/actions"
+	"pdf-extractor/internal/services"
 
 	"github.com/spf13/cobra"
 )
@@ -25,10 +26,19 @@ func init() {
 	DeletePagesCommand.Flags().StringVar(&startsWith, "starts-with", "", "Delete pages where content starts with the specified string")
 	DeletePagesCommand.Flags().BoolVar(&skipBackup, "no-backup", false, "Create a backup of the PDF file before deleting pages")
 	DeletePagesCommand.Flags().StringVar(&backupPath, "backup-path", "./backup", "Path to save backup files")
+	addDryRunFlags(DeletePagesCommand)
 	DeletePagesCommand.MarkFlagRequired("file")
 	rootCmd.AddCommand(DeletePagesCommand)
 }
 func deletePages(cmd *cobra.Command, args []string) error {
+	ext, err := newTextExtractor()
+	if err != nil {
+		return err
+	}
+	pw, err := newPageWriter()
+	if err != nil {
+		return err
+	}
 	var cmds []actions.Command
 	cmds = append(cmds, &actions.DeletePagesSettings{
 		File:       file,
@@ -38,9 +48,13 @@ func deletePages(cmd *cobra.Command, args []string) error {
 		StartsWith: startsWith,
 		BackupPath: backupPath,
 		BackupFlag: !skipBackup,
+		Extractor:  ext,
+		Writer:     pw,
+		PlanFormat: dryRunFormat(),
 	})
 	invoker := actions.Invoker{
 		Command: cmds,
+		Quiet:   dryRunFormat() == services.PlanJSON,
 	}
 	if err := invoker.ExecuteCommand(); err != nil {
 		return err
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"pdf-extractor/internal/actions"
+	"pdf-extractor/internal/extractor"
+	"pdf-extractor/internal/tools"
+	"pdf-extractor/internal/writer"
+
+	"github.com/spf13/cobra"
+)
+
+var DoctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the external tools pdf-extractor depends on",
+	Long:  `The doctor command looks up pdfinfo, pdftotext, pdftk and other optional tools in PATH, reports their versions and fails when a tool required by --text-engine, --page-writer or --ocr is missing`,
+	RunE:  doctor,
+}
+
+func init() {
+	rootCmd.AddCommand(DoctorCmd)
+}
+func doctor(cmd *cobra.Command, args []string) error {
+	ext, err := extractor.New(textEngine, jobs)
+	if err != nil {
+		return err
+	}
+	ext, err = extractor.NewOCRExtractor(ext, ocrMode, jobs)
+	if err != nil {
+		return err
+	}
+	pw, err := writer.New(pageWriter)
+	if err != nil {
+		return err
+	}
+	var cmds []actions.Command
+	cmds = append(cmds, &actions.DoctorSettings{
+		Required: tools.Required(ext, pw),
+	})
+	invoker := actions.Invoker{
+		Command: cmds,
+	}
+	if err := invoker.ExecuteCommand(); err != nil {
+		return err
+	}
+	return nil
+}
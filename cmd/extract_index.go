@@ -2,10 +2,22 @@ package cmd
 
 import (
 	"pdf-extractor/internal/actions"
+	"pdf-extractor/internal/services"
+	"pdf-extractor/internal/toc"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
 
+var (
+	contentsPages string
+	contentsPage  int
+	keywords      []string
+	interactive   bool
+	indexSource   string
+	tocLayout     string
+)
+
 var indexExtractorCmd = &cobra.Command{
 	Use:   "extract-index",
 	Short: "Extract authors and titles from a PDF file",
@@ -17,13 +29,32 @@ func init() {
 	indexExtractorCmd.Flags().StringVarP(&file, "file", "f", "", "Path to the PDF file")
 	indexExtractorCmd.MarkFlagRequired("file")
 	indexExtractorCmd.Flags().StringVarP(&outputPath, "output-path", "o", "./", "Path to save the output files")
+	indexExtractorCmd.Flags().StringVar(&indexSource, "source", services.SourceAuto, "Where to read the articles from (auto|outline|text): auto prefers the PDF outline and parses the contents when there is none")
+	indexExtractorCmd.Flags().StringVar(&contentsPages, "contents-pages", "", "Pages holding the table of contents, e.g. 3-5 (found automatically when empty)")
+	indexExtractorCmd.Flags().IntVar(&contentsPage, "contents-page", 0, "Page the table of contents starts on, skipping its detection")
+	indexExtractorCmd.Flags().StringArrayVar(&keywords, "contents-keyword", nil, "Heading the table of contents is looked for under, replacing the defaults (can be repeated)")
+	indexExtractorCmd.Flags().StringVar(&tocLayout, "layout", toc.LayoutNumbered, "Layout of the table of contents ("+strings.Join(toc.Layouts(), "|")+"), or the path to a YAML layout profile")
+	indexExtractorCmd.Flags().Float64Var(&headerThreshold, "header-threshold", services.DefaultHeaderThreshold, "Share of odd or even pages a line must repeat on to be removed from the contents as a running header or footer")
+	indexExtractorCmd.Flags().BoolVarP(&interactive, "interactive", "i", false, "Review the entries one by one before saving them")
 	rootCmd.AddCommand(indexExtractorCmd)
 }
 func extractIndex(cmd *cobra.Command, args []string) error {
+	ext, err := newTextExtractor()
+	if err != nil {
+		return err
+	}
 	var cmds []actions.Command
 	cmds = append(cmds, &actions.IndexSettings{
-		File:       file,
-		OutputPath: outputPath,
+		File:            file,
+		OutputPath:      outputPath,
+		ContentsPages:   contentsPages,
+		ContentsPage:    contentsPage,
+		Keywords:        keywords,
+		Source:          indexSource,
+		Layout:          tocLayout,
+		HeaderThreshold: headerThreshold,
+		Interactive:     interactive,
+		Extractor:       ext,
 	})
 	invoker := actions.Invoker{
 		Command: cmds,
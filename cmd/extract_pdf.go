@@ -3,12 +3,19 @@ package cmd
 import (
 	"fmt"
 	"pdf-extractor/internal/actions"
+	"pdf-extractor/internal/services"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
-var articleTitle string
+var (
+	articleTitle    string
+	headerThreshold float64
+	matchThreshold  float64
+	nameTemplate    string
+	slug            string
+)
 var PDFExtractorCommand = &cobra.Command{
 	Use:   "extract",
 	Short: "Extract authors and titles from a PDF file",
@@ -34,10 +41,25 @@ func init() {
 	PDFExtractorCommand.Flags().IntVar(&fromPage, "from", -1, "Starting page number to extract from")
 	PDFExtractorCommand.Flags().IntVar(&toPage, "to", -1, "Ending page number to extract to")
 	PDFExtractorCommand.Flags().StringVar(&articleTitle, "article-title", "", "Name of the article")
+	PDFExtractorCommand.Flags().StringArrayVar(&keywords, "contents-keyword", nil, "Heading of the table of contents, whose pages are not searched for titles (defaults to the keywords in config.yaml, can be repeated)")
+	PDFExtractorCommand.Flags().Float64Var(&headerThreshold, "header-threshold", services.DefaultHeaderThreshold, "Share of odd or even pages a line must repeat on to be removed as a running header or footer")
+
+	PDFExtractorCommand.Flags().Float64Var(&matchThreshold, "match-threshold", services.DefaultMatchThreshold, "Similarity between 0 and 1 the top of a page must reach to match a title not found as printed (1 to match exactly)")
+	PDFExtractorCommand.Flags().StringVar(&nameTemplate, "name-template", services.DefaultNameTemplate, "Name of the generated PDFs, with placeholders {index}, {title}, {author}, {number}, {section}, {start}, {end} and {source}, e.g. {index:02}-{title}")
+	PDFExtractorCommand.Flags().StringVar(&slug, "slug", services.SlugUnderscore, "How text is written in file names (underscore|hyphen|keep)")
+	addDryRunFlags(PDFExtractorCommand)
 
 	rootCmd.AddCommand(PDFExtractorCommand)
 }
 func extractPDF(cmd *cobra.Command, args []string) error {
+	ext, err := newTextExtractor()
+	if err != nil {
+		return err
+	}
+	pw, err := newPageWriter()
+	if err != nil {
+		return err
+	}
 	var cmds []actions.Command
 	if fromPage != -1 || toPage != -1 {
 		if endsWith != "" {
@@ -48,16 +70,26 @@ func extractPDF(cmd *cobra.Command, args []string) error {
 		}
 	}
 	cmds = append(cmds, &actions.ExtractPDFSettings{
-		File:         file,
-		OutputPath:   outputPath,
-		ConfigPath:   configPath,
-		EndsWith:     endsWith,
-		FromPage:     fromPage,
-		ToPage:       toPage,
-		ArticleTitle: articleTitle,
+		File:            file,
+		OutputPath:      outputPath,
+		ConfigPath:      configPath,
+		EndsWith:        endsWith,
+		Keywords:        keywords,
+		FromPage:        fromPage,
+		ToPage:          toPage,
+		ArticleTitle:    articleTitle,
+		Extractor:       ext,
+		Writer:          pw,
+		HeaderThreshold: headerThreshold,
+		MatchThreshold:  matchThreshold,
+		NameTemplate:    nameTemplate,
+		Slug:            slug,
+		PlanFormat:      dryRunFormat(),
+		Jobs:            jobs,
 	})
 	invoker := actions.Invoker{
 		Command: cmds,
+		Quiet:   dryRunFormat() == services.PlanJSON,
 	}
 	if err := invoker.ExecuteCommand(); err != nil {
 		return err
@@ -3,6 +3,10 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"pdf-extractor/internal/cache"
+	"pdf-extractor/internal/extractor"
+	"pdf-extractor/internal/writer"
+	"runtime"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -66,5 +70,10 @@ func init() {
 	// that apply to all commands.
 	rootCmd.PersistentFlags().BoolVarP(&debug, "debug", "d", false, "Enable debug mode")
 	rootCmd.PersistentFlags().BoolVarP(&versionFlag, "version", "v", false, "Print the version number of pdf-extractor")
+	rootCmd.PersistentFlags().StringVar(&textEngine, "text-engine", extractor.EngineNative, "Text extraction engine to use (native|pdftotext)")
+	rootCmd.PersistentFlags().StringVar(&pageWriter, "page-writer", writer.WriterPdfcpu, "Page writer used to extract and delete pages (pdfcpu|pdftk)")
+	rootCmd.PersistentFlags().StringVar(&cacheDir, "cache-dir", cache.DefaultDir(), "Directory to cache extracted page text in (empty to disable)")
+	rootCmd.PersistentFlags().StringVar(&ocrMode, "ocr", extractor.OCRAuto, "Recognize text on scanned pages with tesseract (auto|always|never)")
+	rootCmd.PersistentFlags().IntVarP(&jobs, "jobs", "j", runtime.NumCPU(), "Number of pages or articles to process in parallel")
 
 }
@@ -0,0 +1,15 @@
+package actions
+
+import "pdf-extractor/internal/services"
+
+type CacheClearSettings struct {
+	CacheDir string
+}
+
+func (s *CacheClearSettings) Execute() error {
+	return services.ClearCache(s.CacheDir)
+}
+
+func (s *CacheClearSettings) Description() string {
+	return "CacheClearCommand"
+}
@@ -8,6 +8,8 @@ type Command interface {
 
 type Invoker struct {
 	Command []Command
+	// Quiet leaves out the success messages, so that output meant for other programs stays clean
+	Quiet bool
 }
 type Describable interface {
 	Description() string
@@ -20,6 +22,9 @@ func (i *Invoker) ExecuteCommand() error {
 			return err // Return the error if any command fails
 		}
 
+		if i.Quiet {
+			continue
+		}
 		// Check if the command implements the Describable interface
 		if describableCmd, ok := cmd.(Describable); ok {
 			fmt.Printf("Command executed successfully: %s\n", describableCmd.Description())
@@ -6,10 +6,12 @@ type DeleteSettings struct {
 	File       string
 	BackupPath string
 	BackupFlag bool
+	// PlanFormat, when set, prints what would be done in this format instead of deleting the file
+	PlanFormat string
 }
 
 func (s *DeleteSettings) Execute() error {
-	return services.Delete(s.File, s.BackupPath, s.BackupFlag)
+	return services.Delete(s.File, s.BackupPath, s.BackupFlag, s.PlanFormat)
 }
 
 func (s *DeleteSettings) Description() string {
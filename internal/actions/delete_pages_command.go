@@ -1,6 +1,10 @@
 package actions
 
-import "pdf-extractor/internal/services"
+import (
+	"pdf-extractor/internal/extractor"
+	"pdf-extractor/internal/services"
+	"pdf-extractor/internal/writer"
+)
 
 type DeletePagesSettings struct {
 	File       string
@@ -10,10 +14,14 @@ type DeletePagesSettings struct {
 	StartsWith string
 	BackupPath string
 	BackupFlag bool
+	Extractor  extractor.TextExtractor
+	Writer     writer.PageWriter
+	// PlanFormat, when set, prints the pages in this format instead of deleting them
+	PlanFormat string
 }
 
 func (s *DeletePagesSettings) Execute() error {
-	return services.DeletePages(s.File, s.FromPage, s.ToPage, s.AtPage, s.StartsWith, s.BackupPath, s.BackupFlag)
+	return services.DeletePages(s.Extractor, s.Writer, s.File, s.FromPage, s.ToPage, s.AtPage, s.StartsWith, s.BackupPath, s.BackupFlag, s.PlanFormat)
 }
 
 func (s *DeletePagesSettings) Description() string {
@@ -0,0 +1,15 @@
+package actions
+
+import "pdf-extractor/internal/services"
+
+type DoctorSettings struct {
+	Required []string
+}
+
+func (s *DoctorSettings) Execute() error {
+	return services.Doctor(s.Required)
+}
+
+func (s *DoctorSettings) Description() string {
+	return "DoctorCommand"
+}
@@ -1,14 +1,25 @@
 package actions
 
-import "pdf-extractor/internal/services"
+import (
+	"pdf-extractor/internal/extractor"
+	"pdf-extractor/internal/services"
+)
 
 type IndexSettings struct {
-	File       string
-	OutputPath string
+	File            string
+	OutputPath      string
+	ContentsPages   string
+	ContentsPage    int
+	Keywords        []string
+	Source          string
+	Layout          string
+	HeaderThreshold float64
+	Interactive     bool
+	Extractor       extractor.TextExtractor
 }
 
 func (s *IndexSettings) Execute() error {
-	return services.ExtractIndex(s.File, s.OutputPath)
+	return services.ExtractIndex(s.Extractor, s.File, s.OutputPath, s.ContentsPages, s.ContentsPage, s.Keywords, s.Source, s.Layout, s.HeaderThreshold, s.Interactive)
 }
 
 func (s *IndexSettings) Description() string {
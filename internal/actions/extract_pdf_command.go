@@ -1,22 +1,36 @@
 package actions
 
-import "pdf-extractor/internal/services"
+import (
+	"pdf-extractor/internal/extractor"
+	"pdf-extractor/internal/services"
+	"pdf-extractor/internal/writer"
+)
 
 type ExtractPDFSettings struct {
-	File         string
-	OutputPath   string
-	ConfigPath   string
-	EndsWith     string
-	FromPage     int
-	ToPage       int
-	ArticleTitle string
+	File            string
+	OutputPath      string
+	ConfigPath      string
+	EndsWith        string
+	Keywords        []string
+	FromPage        int
+	ToPage          int
+	ArticleTitle    string
+	Extractor       extractor.TextExtractor
+	Writer          writer.PageWriter
+	HeaderThreshold float64
+	MatchThreshold  float64
+	Jobs            int
+	NameTemplate    string
+	Slug            string
+	// PlanFormat, when set, prints the page ranges in this format instead of writing the PDFs
+	PlanFormat string
 }
 
 func (s *ExtractPDFSettings) Execute() error {
 	if s.FromPage != -1 || s.ToPage != -1 {
-		return services.ExtractPDFFromRange(s.File, s.OutputPath, s.FromPage, s.ToPage, s.ArticleTitle)
+		return services.ExtractPDFFromRange(s.Extractor, s.Writer, s.File, s.OutputPath, s.FromPage, s.ToPage, s.ArticleTitle, s.NameTemplate, s.Slug, s.PlanFormat)
 	}
-	return services.ExtractPDF(s.File, s.OutputPath, s.ConfigPath, s.EndsWith)
+	return services.ExtractPDF(s.Extractor, s.Writer, s.File, s.OutputPath, s.ConfigPath, s.EndsWith, s.Keywords, s.HeaderThreshold, s.MatchThreshold, s.Jobs, s.NameTemplate, s.Slug, s.PlanFormat)
 }
 
 func (s *ExtractPDFSettings) Description() string {
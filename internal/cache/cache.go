@@ -0,0 +1,130 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"pdf-extractor/internal/extractor"
+	"strings"
+)
+
+// formatVersion is bumped whenever the stored text would change for the same input,
+// for example when text normalization changes.
+const formatVersion = 5
+
+// Cache stores extracted page text on disk, keyed by the SHA-256 of the PDF contents.
+type Cache struct {
+	Dir string
+	// ReadOnly serves stored entries without storing new ones
+	ReadOnly bool
+}
+
+// Entry holds the raw and normalized text of every page of one document, and
+// its layout once it has been asked for.
+type Entry struct {
+	Raw        []string         `json:"raw"`
+	Normalized []string         `json:"normalized"`
+	Layout     []extractor.Page `json:"layout,omitempty"`
+}
+
+func New(dir string) *Cache {
+	return &Cache{Dir: dir}
+}
+
+// DefaultDir returns the cache directory used when --cache-dir is not given.
+func DefaultDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "pdf-extractor")
+}
+
+// FileKey returns the hex encoded SHA-256 of the file contents.
+func FileKey(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %v", path, err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("failed to hash %s: %v", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (c *Cache) entryPath(key, variant string) string {
+	return filepath.Join(c.Dir, key, fmt.Sprintf("%s.v%d.json", variant, formatVersion))
+}
+
+// Load returns the stored entry for the key and variant, if any.
+func (c *Cache) Load(key, variant string) (*Entry, bool) {
+	data, err := os.ReadFile(c.entryPath(key, variant))
+	if err != nil {
+		return nil, false
+	}
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil || len(entry.Raw) != len(entry.Normalized) {
+		return nil, false
+	}
+	return &entry, true
+}
+
+// Store writes the entry for the key and variant, unless the cache is read-only.
+func (c *Cache) Store(key, variant string, entry *Entry) error {
+	if c.ReadOnly {
+		return nil
+	}
+	path := c.entryPath(key, variant)
+	err := os.MkdirAll(filepath.Dir(path), os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("failed to create cache directory: %v", err)
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %v", err)
+	}
+	// Write to a temporary file first so concurrent runs never see a partial entry
+	tempFile := path + ".tmp"
+	err = os.WriteFile(tempFile, data, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write cache entry: %v", err)
+	}
+	return os.Rename(tempFile, path)
+}
+
+// Clear removes every cached entry. Only directories named after a SHA-256 key are removed,
+// so that pointing --cache-dir at a directory holding other files never deletes them.
+func (c *Cache) Clear() error {
+	entries, err := os.ReadDir(c.Dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read cache directory %s: %v", c.Dir, err)
+	}
+	for _, e := range entries {
+		if !e.IsDir() || !isKey(e.Name()) {
+			continue
+		}
+		err := os.RemoveAll(filepath.Join(c.Dir, e.Name()))
+		if err != nil {
+			return fmt.Errorf("failed to remove cache entry %s: %v", e.Name(), err)
+		}
+	}
+	return nil
+}
+
+// isKey reports whether the name is a key returned by FileKey.
+func isKey(name string) bool {
+	if len(name) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(name)
+	return err == nil && strings.ToLower(name) == name
+}
@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestClear(t *testing.T) {
+	dir := t.TempDir()
+	key := strings.Repeat("ab", 32)
+	c := New(dir)
+	if err := c.Store(key, "native", &Entry{Raw: []string{"a"}, Normalized: []string{"a"}}); err != nil {
+		t.Fatal(err)
+	}
+	// Files and directories that are not cache entries
+	kept := []string{"notes.txt", "photos", strings.ToUpper(key), key + ".txt"}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("notes"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, key+".txt"), []byte("notes"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"photos", strings.ToUpper(key)} {
+		if err := os.MkdirAll(filepath.Join(dir, name, "inner"), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := c.Clear(); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if _, ok := c.Load(key, "native"); ok {
+		t.Errorf("Clear() kept the cached entry")
+	}
+	for _, name := range kept {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("Clear() removed %s: %v", name, err)
+		}
+	}
+}
+
+func TestClearMissingDir(t *testing.T) {
+	if err := New(filepath.Join(t.TempDir(), "missing")).Clear(); err != nil {
+		t.Errorf("Clear() error = %v", err)
+	}
+}
@@ -0,0 +1,171 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"pdf-extractor/internal/extractor"
+	"pdf-extractor/internal/tools"
+	"pdf-extractor/internal/utils"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Extractor wraps a TextExtractor and serves page text from the cache when present.
+type Extractor struct {
+	cache *Cache
+	next  extractor.TextExtractor
+
+	mu      sync.Mutex
+	path    string
+	modTime time.Time
+	size    int64
+	key     string
+	entry   *Entry
+}
+
+func NewExtractor(cache *Cache, next extractor.TextExtractor) *Extractor {
+	return &Extractor{cache: cache, next: next}
+}
+
+func (e *Extractor) Name() string {
+	return e.next.Name()
+}
+
+func (e *Extractor) RequiredTools() []string {
+	return tools.Required(e.next)
+}
+
+// load returns the cached entry for the file, extracting and storing it on a miss. Without
+// extract a miss returns no entry instead.
+func (e *Extractor) load(pdfPath string, extract bool) (*Entry, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	info, err := os.Stat(pdfPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", pdfPath, err)
+	}
+	if e.entry != nil && e.path == pdfPath && e.modTime.Equal(info.ModTime()) && e.size == info.Size() {
+		return e.entry, nil
+	}
+
+	key, err := FileKey(pdfPath)
+	if err != nil {
+		return nil, err
+	}
+	entry, ok := e.cache.Load(key, e.next.Name())
+	if ok {
+		logrus.Debugf("Using cached page text for %s (%s)", pdfPath, key)
+	} else if !extract {
+		return nil, nil
+	} else {
+		entry, err = e.extract(pdfPath)
+		if err != nil {
+			return nil, err
+		}
+		// A cache that cannot be written should never fail the command
+		if err := e.cache.Store(key, e.next.Name(), entry); err != nil {
+			logrus.Warnf("Failed to cache page text for %s: %v", pdfPath, err)
+		}
+	}
+
+	e.path, e.modTime, e.size, e.entry = pdfPath, info.ModTime(), info.Size(), entry
+	e.key = key
+	return entry, nil
+}
+
+// extract reads every page with the wrapped extractor. When it reports the layout, the text is
+// read from the layout, so that the document is extracted once for both.
+func (e *Extractor) extract(pdfPath string) (*Entry, error) {
+	entry := &Entry{}
+	if l, ok := e.next.(extractor.LayoutExtractor); ok {
+		layout, err := l.ExtractLayout(pdfPath)
+		if err != nil {
+			return nil, err
+		}
+		entry.Layout = layout
+		entry.Raw = make([]string, len(layout))
+		for i, page := range layout {
+			entry.Raw[i] = page.Text()
+		}
+	} else {
+		raw, err := e.next.ExtractPages(pdfPath)
+		if err != nil {
+			return nil, err
+		}
+		entry.Raw = raw
+	}
+	entry.Normalized = make([]string, len(entry.Raw))
+	for i, content := range entry.Raw {
+		entry.Normalized[i] = utils.NormalizeText(content)
+	}
+	return entry, nil
+}
+
+// PageCount reads the count from the cache when the file is there, and otherwise asks the
+// wrapped extractor, so that checking a page number never extracts the whole document.
+func (e *Extractor) PageCount(pdfPath string) (int, error) {
+	entry, err := e.load(pdfPath, false)
+	if err != nil {
+		return 0, err
+	}
+	if entry == nil {
+		return e.next.PageCount(pdfPath)
+	}
+	return len(entry.Raw), nil
+}
+
+func (e *Extractor) ExtractPage(pdfPath string, page int) (string, error) {
+	entry, err := e.load(pdfPath, true)
+	if err != nil {
+		return "", err
+	}
+	if page < 1 || page > len(entry.Raw) {
+		return "", fmt.Errorf("invalid page number: %d (total pages: %d)", page, len(entry.Raw))
+	}
+	return entry.Raw[page-1], nil
+}
+
+func (e *Extractor) ExtractPages(pdfPath string) ([]string, error) {
+	entry, err := e.load(pdfPath, true)
+	if err != nil {
+		return nil, err
+	}
+	return entry.Raw, nil
+}
+
+func (e *Extractor) ExtractNormalizedPages(pdfPath string) ([]string, error) {
+	entry, err := e.load(pdfPath, true)
+	if err != nil {
+		return nil, err
+	}
+	return entry.Normalized, nil
+}
+
+func (e *Extractor) ExtractLayout(pdfPath string) ([]extractor.Page, error) {
+	entry, err := e.load(pdfPath, true)
+	if err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if entry.Layout != nil {
+		return entry.Layout, nil
+	}
+	l, ok := e.next.(extractor.LayoutExtractor)
+	if !ok {
+		return nil, fmt.Errorf("text engine '%s' does not report page layout", e.next.Name())
+	}
+	layout, err := l.ExtractLayout(pdfPath)
+	if err != nil {
+		return nil, err
+	}
+	entry.Layout = layout
+	if err := e.cache.Store(e.key, e.next.Name(), entry); err != nil {
+		logrus.Warnf("Failed to cache page layout for %s: %v", pdfPath, err)
+	}
+	return layout, nil
+}
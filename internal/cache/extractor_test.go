@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// countingExtractor returns the contents of the file as its only page and counts how often
+// it is asked for them.
+type countingExtractor struct {
+	name  string
+	calls int
+}
+
+func (c *countingExtractor) Name() string { return c.name }
+
+func (c *countingExtractor) PageCount(pdfPath string) (int, error) { return 1, nil }
+
+func (c *countingExtractor) ExtractPage(pdfPath string, page int) (string, error) {
+	pages, err := c.ExtractPages(pdfPath)
+	if err != nil {
+		return "", err
+	}
+	return pages[0], nil
+}
+
+func (c *countingExtractor) ExtractPages(pdfPath string) ([]string, error) {
+	c.calls++
+	data, err := os.ReadFile(pdfPath)
+	if err != nil {
+		return nil, err
+	}
+	return []string{string(data)}, nil
+}
+
+func TestExtractorCache(t *testing.T) {
+	c := New(t.TempDir())
+	pdfPath := filepath.Join(t.TempDir(), "journal.pdf")
+	if err := os.WriteFile(pdfPath, []byte("Rivers"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	native := &countingExtractor{name: "native"}
+	extract := func(next *countingExtractor) string {
+		t.Helper()
+		// A new extractor for every run, as for every command, reads from the cache on disk
+		pages, err := NewExtractor(c, next).ExtractPages(pdfPath)
+		if err != nil {
+			t.Fatalf("ExtractPages() error = %v", err)
+		}
+		return pages[0]
+	}
+
+	extract(native)
+	if got := extract(native); got != "Rivers" || native.calls != 1 {
+		t.Errorf("second run = %q after %d extractions, want Rivers after 1", got, native.calls)
+	}
+
+	// A different engine has entries of its own
+	pdftotext := &countingExtractor{name: "pdftotext"}
+	if extract(pdftotext); pdftotext.calls != 1 {
+		t.Errorf("another engine was served the cached text of native")
+	}
+
+	// A changed PDF is another key
+	if err := os.WriteFile(pdfPath, []byte("Lakes"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := extract(native); got != "Lakes" || native.calls != 2 {
+		t.Errorf("changed PDF = %q after %d extractions, want Lakes after 2", got, native.calls)
+	}
+}
+
+func TestExtractorReadOnly(t *testing.T) {
+	c := New(t.TempDir())
+	c.ReadOnly = true
+	pdfPath := filepath.Join(t.TempDir(), "journal.pdf")
+	if err := os.WriteFile(pdfPath, []byte("Rivers"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	next := &countingExtractor{name: "native"}
+	for range 2 {
+		if _, err := NewExtractor(c, next).ExtractPages(pdfPath); err != nil {
+			t.Fatalf("ExtractPages() error = %v", err)
+		}
+	}
+	if next.calls != 2 {
+		t.Errorf("a read-only cache stored the page text")
+	}
+}
@@ -0,0 +1,342 @@
+package extractor
+
+import (
+	"bytes"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// operand is a value pushed onto the operand stack of a content stream.
+type operand struct {
+	kind   byte // 'n' number, 's' string, '/' name, '[' array, '<' dict
+	num    float64
+	str    []byte
+	name   string
+	values []operand
+}
+
+// contentLexer tokenizes a PDF content stream.
+type contentLexer struct {
+	data []byte
+	pos  int
+}
+
+func isWhitespace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f' || c == 0
+}
+
+func isDelimiter(c byte) bool {
+	return strings.IndexByte("()<>[]{}/%", c) >= 0
+}
+
+func (l *contentLexer) skipSpace() {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		if isWhitespace(c) {
+			l.pos++
+		} else if c == '%' {
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+		} else {
+			return
+		}
+	}
+}
+
+// next returns the next operand, or the next operator when isOp is true.
+func (l *contentLexer) next() (op operand, operator string, isOp bool, ok bool) {
+	l.skipSpace()
+	if l.pos >= len(l.data) {
+		return operand{}, "", false, false
+	}
+	c := l.data[l.pos]
+	switch {
+	case c == '(':
+		return operand{kind: 's', str: l.literalString()}, "", false, true
+	case c == '<' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '<':
+		l.pos += 2
+		return operand{kind: '<', values: l.collect(">>")}, "", false, true
+	case c == '<':
+		return operand{kind: 's', str: l.hexString()}, "", false, true
+	case c == '[':
+		l.pos++
+		return operand{kind: '[', values: l.collect("]")}, "", false, true
+	case c == '/':
+		l.pos++
+		return operand{kind: '/', name: l.word()}, "", false, true
+	case c == ']' || c == '>' || c == ')' || c == '{' || c == '}':
+		l.pos++
+		return l.next()
+	}
+	w := l.word()
+	if w == "" {
+		l.pos++
+		return l.next()
+	}
+	if n, err := strconv.ParseFloat(w, 64); err == nil {
+		return operand{kind: 'n', num: n}, "", false, true
+	}
+	return operand{}, w, true, true
+}
+
+// collect reads operands until the given closing token, ignoring operators.
+func (l *contentLexer) collect(closing string) []operand {
+	var values []operand
+	for {
+		l.skipSpace()
+		if l.pos >= len(l.data) {
+			return values
+		}
+		if bytes.HasPrefix(l.data[l.pos:], []byte(closing)) {
+			l.pos += len(closing)
+			return values
+		}
+		op, _, isOp, ok := l.next()
+		if !ok {
+			return values
+		}
+		if !isOp {
+			values = append(values, op)
+		}
+	}
+}
+
+func (l *contentLexer) word() string {
+	start := l.pos
+	for l.pos < len(l.data) && !isWhitespace(l.data[l.pos]) && !isDelimiter(l.data[l.pos]) {
+		l.pos++
+	}
+	return string(l.data[start:l.pos])
+}
+
+func (l *contentLexer) literalString() []byte {
+	var out []byte
+	depth := 0
+	l.pos++ // opening parenthesis
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+			out = append(out, c)
+		case ')':
+			if depth == 0 {
+				return out
+			}
+			depth--
+			out = append(out, c)
+		case '\\':
+			if l.pos >= len(l.data) {
+				return out
+			}
+			e := l.data[l.pos]
+			l.pos++
+			switch e {
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case 'b':
+				out = append(out, '\b')
+			case 'f':
+				out = append(out, '\f')
+			case '\r':
+				if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+			case '\n':
+			default:
+				if e >= '0' && e <= '7' {
+					v := int(e - '0')
+					for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						v = v*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					out = append(out, byte(v))
+				} else {
+					out = append(out, e)
+				}
+			}
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func (l *contentLexer) hexString() []byte {
+	l.pos++ // opening angle bracket
+	var digits []byte
+	for l.pos < len(l.data) && l.data[l.pos] != '>' {
+		c := l.data[l.pos]
+		if !isWhitespace(c) {
+			digits = append(digits, c)
+		}
+		l.pos++
+	}
+	l.pos++ // closing angle bracket
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	out := make([]byte, len(digits)/2)
+	for i := range out {
+		v, _ := strconv.ParseUint(string(digits[2*i:2*i+2]), 16, 8)
+		out[i] = byte(v)
+	}
+	return out
+}
+
+// skipInlineImage advances past the binary data of an inline image (BI ... ID <data> EI).
+func (l *contentLexer) skipInlineImage() {
+	idx := bytes.Index(l.data[l.pos:], []byte("ID"))
+	if idx < 0 {
+		l.pos = len(l.data)
+		return
+	}
+	l.pos += idx + 2
+	for {
+		end := bytes.Index(l.data[l.pos:], []byte("EI"))
+		if end < 0 {
+			l.pos = len(l.data)
+			return
+		}
+		start := l.pos + end
+		l.pos = start + 2
+		// EI only ends the image when it stands on its own
+		if start > 0 && isWhitespace(l.data[start-1]) && (l.pos >= len(l.data) || isWhitespace(l.data[l.pos])) {
+			return
+		}
+	}
+}
+
+// matrix is an affine transformation [a b c d e f].
+type matrix [6]float64
+
+var identity = matrix{1, 0, 0, 1, 0, 0}
+
+func (m matrix) multiply(n matrix) matrix {
+	return matrix{
+		m[0]*n[0] + m[1]*n[2],
+		m[0]*n[1] + m[1]*n[3],
+		m[2]*n[0] + m[3]*n[2],
+		m[2]*n[1] + m[3]*n[3],
+		m[4]*n[0] + m[5]*n[2] + n[4],
+		m[4]*n[1] + m[5]*n[3] + n[5],
+	}
+}
+
+func (m matrix) apply(x, y float64) (float64, float64) {
+	return m[0]*x + m[2]*y + m[4], m[1]*x + m[3]*y + m[5]
+}
+
+// textSpan is a run of text shown by a single text operator.
+type textSpan struct {
+	x, y     float64
+	width    float64
+	size     float64
+	fontName string
+	text     string
+}
+
+// columnGap is the gap between spans, in multiples of the font size, that separates columns.
+const columnGap = 3.0
+
+// textLine is a group of spans sharing a baseline.
+type textLine struct {
+	spans []textSpan
+	y     float64
+}
+
+// groupLines sorts spans into lines from the top of the page to the bottom.
+func groupLines(spans []textSpan) []textLine {
+	sorted := make([]textSpan, 0, len(spans))
+	for _, s := range spans {
+		if strings.TrimSpace(s.text) != "" {
+			sorted = append(sorted, s)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].y > sorted[j].y
+	})
+
+	var lines []textLine
+	for _, s := range sorted {
+		tolerance := math.Max(s.size*0.4, 1)
+		if n := len(lines); n > 0 && math.Abs(lines[n-1].y-s.y) <= tolerance {
+			lines[n-1].spans = append(lines[n-1].spans, s)
+			continue
+		}
+		lines = append(lines, textLine{spans: []textSpan{s}, y: s.y})
+	}
+	var split []textLine
+	for _, line := range lines {
+		sort.SliceStable(line.spans, func(a, b int) bool {
+			return line.spans[a].x < line.spans[b].x
+		})
+		// Text set in columns shares baselines, so a wide gap starts a separate line
+		start := 0
+		for i := 1; i < len(line.spans); i++ {
+			prev, s := line.spans[i-1], line.spans[i]
+			if s.x-(prev.x+prev.width) > math.Max(s.size, prev.size)*columnGap {
+				split = append(split, textLine{spans: line.spans[start:i], y: line.y})
+				start = i
+			}
+		}
+		split = append(split, textLine{spans: line.spans[start:], y: line.y})
+	}
+	return split
+}
+
+// text joins the spans of a line, inserting spaces where there are visible gaps.
+func (l textLine) text() string {
+	var b strings.Builder
+	for i, s := range l.spans {
+		if i > 0 {
+			prev := l.spans[i-1]
+			gap := s.x - (prev.x + prev.width)
+			if gap > s.size*0.15 && !strings.HasSuffix(prev.text, " ") && !strings.HasPrefix(s.text, " ") {
+				b.WriteString(" ")
+			}
+		}
+		b.WriteString(s.text)
+	}
+	return strings.TrimRight(b.String(), " ")
+}
+
+// layout returns the line with its bounding box measured from the top-left corner of the media box.
+func (l textLine) layout(mediaBox *types.Rectangle) Line {
+	line := Line{Text: l.text(), XMin: math.Inf(1), XMax: math.Inf(-1)}
+	longest := 0
+	for _, s := range l.spans {
+		line.XMin = math.Min(line.XMin, s.x)
+		line.XMax = math.Max(line.XMax, s.x+s.width)
+		line.FontSize = math.Max(line.FontSize, s.size)
+		if n := len(strings.TrimSpace(s.text)); n > longest {
+			longest = n
+			line.FontName = baseFontName(s.fontName)
+		}
+	}
+	// Spans only carry their baseline, so assume typical ascent and descent
+	baseline := mediaBox.UR.Y - l.y
+	line.XMin -= mediaBox.LL.X
+	line.XMax -= mediaBox.LL.X
+	line.YMin = baseline - line.FontSize*0.8
+	line.YMax = baseline + line.FontSize*0.2
+	return line
+}
+
+// baseFontName strips the subset tag (e.g. "ABCDEF+") from a font name.
+func baseFontName(name string) string {
+	if i := strings.IndexByte(name, '+'); i == 6 {
+		return name[i+1:]
+	}
+	return name
+}
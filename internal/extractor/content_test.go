@@ -0,0 +1,253 @@
+package extractor
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// token is an operand or operator read by the lexer, written the way tests compare them.
+type token struct {
+	kind byte
+	text string
+}
+
+func lex(data string) []token {
+	var tokens []token
+	l := &contentLexer{data: []byte(data)}
+	for {
+		op, operator, isOp, ok := l.next()
+		if !ok {
+			return tokens
+		}
+		switch {
+		case isOp:
+			tokens = append(tokens, token{'o', operator})
+		case op.kind == 's':
+			tokens = append(tokens, token{'s', string(op.str)})
+		case op.kind == '/':
+			tokens = append(tokens, token{'/', op.name})
+		case op.kind == 'n':
+			tokens = append(tokens, token{'n', types.Float(op.num).String()})
+		default:
+			tokens = append(tokens, token{op.kind, ""})
+		}
+	}
+}
+
+func TestContentLexer(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []token
+	}{
+		{
+			name: "operators and operands",
+			data: "BT /F1 12 Tf 72 -700.5 Td (Hi) Tj ET",
+			want: []token{{'o', "BT"}, {'/', "F1"}, {'n', "12.00"}, {'o', "Tf"}, {'n', "72.00"}, {'n', "-700.50"}, {'o', "Td"}, {'s', "Hi"}, {'o', "Tj"}, {'o', "ET"}},
+		},
+		{
+			name: "comments",
+			data: "% a comment (not a string) Tj\nq % another\rQ",
+			want: []token{{'o', "q"}, {'o', "Q"}},
+		},
+		{
+			name: "names end at delimiters",
+			data: "/F1/F2[/F3]",
+			want: []token{{'/', "F1"}, {'/', "F2"}, {'[', ""}},
+		},
+		{
+			name: "dictionaries",
+			data: "/Span <</ActualText (x) /MCID 0>> BDC",
+			want: []token{{'/', "Span"}, {'<', ""}, {'o', "BDC"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lex(tt.data); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lex(%q) = %v, want %v", tt.data, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContentLexerStrings(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"literal", "(Hello)", "Hello"},
+		{"balanced parentheses", "(a (b) c)", "a (b) c"},
+		{"escaped parentheses", `(a \( b)`, "a ( b"},
+		{"escapes", `(a\nb\tc\\d)`, "a\nb\tc\\d"},
+		{"octal", `(\101\60\0611)`, "A011"},
+		{"line continuation", "(ab\\\ncd)", "abcd"},
+		{"hex", "<48 65 6C 6c 6F>", "Hello"},
+		{"odd hex digits", "<414>", "A@"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &contentLexer{data: []byte(tt.data)}
+			op, _, isOp, ok := l.next()
+			if !ok || isOp || op.kind != 's' {
+				t.Fatalf("next() on %q did not return a string", tt.data)
+			}
+			if string(op.str) != tt.want {
+				t.Errorf("string %q = %q, want %q", tt.data, op.str, tt.want)
+			}
+		})
+	}
+}
+
+func TestContentLexerArrays(t *testing.T) {
+	l := &contentLexer{data: []byte("[(A) -250 (B) <43>] TJ")}
+	op, _, _, _ := l.next()
+	if op.kind != '[' || len(op.values) != 4 {
+		t.Fatalf("array = %+v, want 4 values", op)
+	}
+	if string(op.values[0].str) != "A" || op.values[1].num != -250 || string(op.values[3].str) != "C" {
+		t.Errorf("array values = %+v", op.values)
+	}
+	if _, operator, isOp, _ := l.next(); !isOp || operator != "TJ" {
+		t.Errorf("operator after the array = %q, want TJ", operator)
+	}
+}
+
+func TestSkipInlineImage(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []token
+	}{
+		{
+			name: "image data",
+			data: "BI /W 2 /H 1 ID \x00\xffEI\x01 EI Q",
+			want: []token{{'o', "BI"}, {'o', "Q"}},
+		},
+		{
+			name: "no end",
+			data: "BI /W 2 ID abc",
+			want: []token{{'o', "BI"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []token
+			l := &contentLexer{data: []byte(tt.data)}
+			for {
+				_, operator, isOp, ok := l.next()
+				if !ok {
+					break
+				}
+				if isOp {
+					got = append(got, token{'o', operator})
+				}
+				if operator == "BI" {
+					l.skipInlineImage()
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("operators = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatrix(t *testing.T) {
+	// Scale by 2, then move by (10, 20)
+	m := matrix{2, 0, 0, 2, 0, 0}.multiply(matrix{1, 0, 0, 1, 10, 20})
+	if x, y := m.apply(3, 4); x != 16 || y != 28 {
+		t.Errorf("apply(3, 4) = (%v, %v), want (16, 28)", x, y)
+	}
+	if got := identity.multiply(m); got != m {
+		t.Errorf("identity.multiply(m) = %v, want %v", got, m)
+	}
+}
+
+func TestGroupLines(t *testing.T) {
+	tests := []struct {
+		name  string
+		spans []textSpan
+		want  []string
+	}{
+		{
+			name: "top to bottom",
+			spans: []textSpan{
+				{x: 72, y: 600, width: 30, size: 10, text: "second"},
+				{x: 72, y: 700, width: 30, size: 10, text: "first"},
+			},
+			want: []string{"first", "second"},
+		},
+		{
+			name: "spans on a baseline",
+			spans: []textSpan{
+				{x: 110, y: 700, width: 30, size: 10, text: "world"},
+				{x: 72, y: 700.5, width: 30, size: 10, text: "hello"},
+			},
+			want: []string{"hello world"},
+		},
+		{
+			name: "kerned spans without a gap",
+			spans: []textSpan{
+				{x: 72, y: 700, width: 10, size: 10, text: "Wa"},
+				{x: 82.5, y: 700, width: 10, size: 10, text: "ter"},
+			},
+			want: []string{"Water"},
+		},
+		{
+			name: "columns",
+			spans: []textSpan{
+				{x: 72, y: 700, width: 100, size: 10, text: "left column"},
+				{x: 300, y: 700, width: 100, size: 10, text: "right column"},
+			},
+			want: []string{"left column", "right column"},
+		},
+		{
+			name: "blank spans",
+			spans: []textSpan{
+				{x: 72, y: 700, width: 10, size: 10, text: "  "},
+				{x: 72, y: 600, width: 10, size: 10, text: "text"},
+			},
+			want: []string{"text"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, l := range groupLines(tt.spans) {
+				got = append(got, l.text())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lines = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTextLineLayout(t *testing.T) {
+	mediaBox := types.NewRectangle(0, 0, 600, 800)
+	line := textLine{y: 700, spans: []textSpan{
+		{x: 72, width: 20, size: 12, fontName: "ABCDEF+Times-Bold", text: "Title"},
+		{x: 100, width: 10, size: 10, fontName: "Times-Roman", text: "1"},
+	}}
+	got := line.layout(mediaBox)
+	want := Line{Text: "Title 1", XMin: 72, XMax: 110, FontSize: 12, FontName: "Times-Bold", YMin: 100 - 12*0.8, YMax: 100 + 12*0.2}
+	if got != want {
+		t.Errorf("layout() = %+v, want %+v", got, want)
+	}
+}
+
+func TestBaseFontName(t *testing.T) {
+	tests := map[string]string{
+		"ABCDEF+Times-Roman": "Times-Roman",
+		"Times-Roman":        "Times-Roman",
+		"AB+Font":            "AB+Font",
+	}
+	for name, want := range tests {
+		if got := baseFontName(name); got != want {
+			t.Errorf("baseFontName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
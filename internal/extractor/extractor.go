@@ -0,0 +1,37 @@
+package extractor
+
+import "fmt"
+
+const (
+	EngineNative    = "native"
+	EnginePdftotext = "pdftotext"
+)
+
+// TextExtractor extracts plain text from the pages of a PDF file.
+type TextExtractor interface {
+	// Name returns the engine name as accepted by --text-engine
+	Name() string
+	PageCount(pdfPath string) (int, error)
+	// ExtractPage returns the text of a single 1-indexed page
+	ExtractPage(pdfPath string, page int) (string, error)
+	// ExtractPages returns the text of every page in the document in a single pass
+	ExtractPages(pdfPath string) ([]string, error)
+}
+
+// NormalizedExtractor is implemented by extractors that can return normalized
+// page text without normalizing it again, such as the page text cache.
+type NormalizedExtractor interface {
+	ExtractNormalizedPages(pdfPath string) ([]string, error)
+}
+
+// New returns the text extractor for the given engine name, extracting
+// up to jobs pages at the same time.
+func New(engine string, jobs int) (TextExtractor, error) {
+	switch engine {
+	case EngineNative:
+		return NewNativeExtractor(jobs), nil
+	case EnginePdftotext:
+		return &PdftotextExtractor{Jobs: jobs}, nil
+	}
+	return nil, fmt.Errorf("unknown text engine '%s': expected '%s' or '%s'", engine, EngineNative, EnginePdftotext)
+}
@@ -0,0 +1,313 @@
+package extractor
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// pdfFont decodes the character codes of a font into unicode text.
+type pdfFont struct {
+	name         string
+	twoByte      bool
+	toUnicode    *cmap
+	encoding     [256]string
+	widths       map[int]float64
+	defaultWidth float64
+}
+
+// glyph is a single decoded character code.
+type glyph struct {
+	text  string
+	width float64 // in text space units (1/1000 em)
+	space bool
+}
+
+// cmap holds the code to unicode mappings of a ToUnicode stream.
+type cmap struct {
+	codeLengths map[int]bool
+	mappings    map[int]string
+}
+
+// glyphNames maps glyph names frequently found in /Differences arrays to text.
+var glyphNames = map[string]string{
+	"space": " ", "exclam": "!", "quotedbl": "\"", "numbersign": "#", "dollar": "$", "percent": "%",
+	"ampersand": "&", "quotesingle": "'", "quoteright": "’", "quoteleft": "‘", "parenleft": "(",
+	"parenright": ")", "asterisk": "*", "plus": "+", "comma": ",", "hyphen": "-", "period": ".",
+	"slash": "/", "zero": "0", "one": "1", "two": "2", "three": "3", "four": "4", "five": "5",
+	"six": "6", "seven": "7", "eight": "8", "nine": "9", "colon": ":", "semicolon": ";", "less": "<",
+	"equal": "=", "greater": ">", "question": "?", "at": "@", "bracketleft": "[", "backslash": "\\",
+	"bracketright": "]", "underscore": "_", "braceleft": "{", "bar": "|", "braceright": "}",
+	"endash": "–", "emdash": "—", "quotedblleft": "“", "quotedblright": "”", "bullet": "•",
+	"fi": "fi", "fl": "fl", "ff": "ff", "ffi": "ffi", "ffl": "ffl", "ellipsis": "…", "dagger": "†",
+	"copyright": "©", "registered": "®", "section": "§", "degree": "°", "minus": "−",
+}
+
+func glyphNameToText(name string) string {
+	if len(name) == 1 {
+		return name
+	}
+	if t, ok := glyphNames[name]; ok {
+		return t
+	}
+	for _, prefix := range []string{"uni", "u"} {
+		if strings.HasPrefix(name, prefix) && len(name) >= len(prefix)+4 {
+			if v, err := strconv.ParseUint(name[len(prefix):len(prefix)+4], 16, 32); err == nil {
+				return string(rune(v))
+			}
+		}
+	}
+	return ""
+}
+
+func baseEncoding(name string) [256]string {
+	var enc [256]string
+	cm := charmap.Windows1252
+	if name == "MacRomanEncoding" {
+		cm = charmap.Macintosh
+	}
+	for i := 0; i < 256; i++ {
+		if i < 32 {
+			continue
+		}
+		enc[i] = string(cm.DecodeByte(byte(i)))
+	}
+	if name == "StandardEncoding" {
+		enc['\''] = "’"
+		enc['`'] = "‘"
+	}
+	return enc
+}
+
+// loadFont resolves a font dictionary into a pdfFont.
+func loadFont(xRefTable *model.XRefTable, o types.Object) *pdfFont {
+	f := &pdfFont{widths: map[int]float64{}, defaultWidth: 500}
+	d, err := xRefTable.DereferenceDict(o)
+	if err != nil || d == nil {
+		f.encoding = baseEncoding("")
+		return f
+	}
+	if bf := d.NameEntry("BaseFont"); bf != nil {
+		f.name = *bf
+	}
+	subtype := ""
+	if st := d.Subtype(); st != nil {
+		subtype = *st
+	}
+
+	if tu, found := d.Find("ToUnicode"); found {
+		if sd, _, err := xRefTable.DereferenceStreamDict(tu); err == nil && sd != nil {
+			if err := sd.Decode(); err == nil {
+				f.toUnicode = parseCMap(sd.Content)
+			}
+		}
+	}
+
+	if subtype == "Type0" {
+		f.twoByte = true
+		f.defaultWidth = 1000
+		if a, err := xRefTable.DereferenceArray(d["DescendantFonts"]); err == nil && len(a) > 0 {
+			if dd, err := xRefTable.DereferenceDict(a[0]); err == nil && dd != nil {
+				loadCIDWidths(xRefTable, dd, f)
+			}
+		}
+		return f
+	}
+
+	encName := ""
+	var differences types.Array
+	if e, found := d.Find("Encoding"); found {
+		e, _ = xRefTable.Dereference(e)
+		switch e := e.(type) {
+		case types.Name:
+			encName = e.Value()
+		case types.Dict:
+			if n := e.NameEntry("BaseEncoding"); n != nil {
+				encName = *n
+			}
+			differences, _ = xRefTable.DereferenceArray(e["Differences"])
+		}
+	}
+	f.encoding = baseEncoding(encName)
+	code := 0
+	for _, o := range differences {
+		o, _ = xRefTable.Dereference(o)
+		switch v := o.(type) {
+		case types.Integer:
+			code = v.Value()
+		case types.Name:
+			if code >= 0 && code < 256 {
+				f.encoding[code] = glyphNameToText(v.Value())
+			}
+			code++
+		}
+	}
+
+	if fc := d.IntEntry("FirstChar"); fc != nil {
+		if w, err := xRefTable.DereferenceArray(d["Widths"]); err == nil {
+			for i, o := range w {
+				if v, err := xRefTable.DereferenceNumber(o); err == nil {
+					f.widths[*fc+i] = v
+				}
+			}
+		}
+	}
+	return f
+}
+
+func loadCIDWidths(xRefTable *model.XRefTable, d types.Dict, f *pdfFont) {
+	if dw, err := xRefTable.DereferenceNumber(d["DW"]); err == nil && dw > 0 {
+		f.defaultWidth = dw
+	}
+	w, err := xRefTable.DereferenceArray(d["W"])
+	if err != nil {
+		return
+	}
+	for i := 0; i < len(w); {
+		first, err := xRefTable.DereferenceNumber(w[i])
+		if err != nil || i+1 >= len(w) {
+			return
+		}
+		if a, err := xRefTable.DereferenceArray(w[i+1]); err == nil && a != nil {
+			for j, o := range a {
+				if v, err := xRefTable.DereferenceNumber(o); err == nil {
+					f.widths[int(first)+j] = v
+				}
+			}
+			i += 2
+			continue
+		}
+		if i+2 >= len(w) {
+			return
+		}
+		last, err1 := xRefTable.DereferenceNumber(w[i+1])
+		v, err2 := xRefTable.DereferenceNumber(w[i+2])
+		if err1 != nil || err2 != nil {
+			return
+		}
+		for c := int(first); c <= int(last); c++ {
+			f.widths[c] = v
+		}
+		i += 3
+	}
+}
+
+// decode splits a shown string into glyphs.
+func (f *pdfFont) decode(b []byte) []glyph {
+	var glyphs []glyph
+	for i := 0; i < len(b); {
+		n := 1
+		if f.twoByte {
+			n = 2
+		}
+		if f.toUnicode != nil && len(f.toUnicode.codeLengths) > 0 {
+			n = f.toUnicode.codeLength(b[i:])
+		}
+		if i+n > len(b) {
+			n = len(b) - i
+		}
+		code := 0
+		for _, c := range b[i : i+n] {
+			code = code<<8 | int(c)
+		}
+		i += n
+
+		text := ""
+		if f.toUnicode != nil {
+			text = f.toUnicode.mappings[code]
+		}
+		if text == "" && !f.twoByte && code < 256 {
+			text = f.encoding[code]
+		}
+		width, ok := f.widths[code]
+		if !ok {
+			width = f.defaultWidth
+		}
+		glyphs = append(glyphs, glyph{text: text, width: width, space: n == 1 && code == 32})
+	}
+	return glyphs
+}
+
+func (c *cmap) codeLength(b []byte) int {
+	for n := 1; n <= 4 && n <= len(b); n++ {
+		if c.codeLengths[n] {
+			return n
+		}
+	}
+	return 1
+}
+
+func utf16Text(b []byte) string {
+	if len(b)%2 == 1 {
+		return string(b)
+	}
+	units := make([]uint16, len(b)/2)
+	for i := range units {
+		units[i] = uint16(b[2*i])<<8 | uint16(b[2*i+1])
+	}
+	return string(utf16.Decode(units))
+}
+
+func bytesToCode(b []byte) int {
+	code := 0
+	for _, c := range b {
+		code = code<<8 | int(c)
+	}
+	return code
+}
+
+// parseCMap reads the codespace ranges and bfchar/bfrange mappings of a ToUnicode CMap.
+func parseCMap(data []byte) *cmap {
+	c := &cmap{codeLengths: map[int]bool{}, mappings: map[int]string{}}
+	l := &contentLexer{data: data}
+	var stack []operand
+	for {
+		op, operator, isOp, ok := l.next()
+		if !ok {
+			break
+		}
+		if !isOp {
+			stack = append(stack, op)
+			continue
+		}
+		switch operator {
+		case "endcodespacerange":
+			for i := 0; i+1 < len(stack); i += 2 {
+				c.codeLengths[len(stack[i].str)] = true
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(stack); i += 2 {
+				c.mappings[bytesToCode(stack[i].str)] = utf16Text(stack[i+1].str)
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(stack); i += 3 {
+				lo, hi := bytesToCode(stack[i].str), bytesToCode(stack[i+1].str)
+				if hi-lo > 0xFFFF {
+					continue
+				}
+				dst := stack[i+2]
+				if dst.kind == '[' {
+					for j, v := range dst.values {
+						c.mappings[lo+j] = utf16Text(v.str)
+					}
+					continue
+				}
+				base := []rune(utf16Text(dst.str))
+				if len(base) == 0 {
+					continue
+				}
+				for code := lo; code <= hi; code++ {
+					r := append([]rune{}, base...)
+					r[len(r)-1] += rune(code - lo)
+					c.mappings[code] = string(r)
+				}
+			}
+		}
+		stack = stack[:0]
+	}
+	return c
+}
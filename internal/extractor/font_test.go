@@ -0,0 +1,183 @@
+package extractor
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+const toUnicodeCMap = `/CIDInit /ProcSet findresource begin
+12 dict begin
+begincmap
+/CMapName /Adobe-Identity-UCS def
+1 begincodespacerange
+<0000> <FFFF>
+endcodespacerange
+2 beginbfchar
+<0003> <0020>
+<0010> <00660069>
+endbfchar
+2 beginbfrange
+<0024> <0026> <0041>
+<0030> <0031> [<0915> <D835DC00>]
+endbfrange
+endcmap
+CMapName currentdict /CMap defineresource pop
+end
+end`
+
+func TestParseCMap(t *testing.T) {
+	c := parseCMap([]byte(toUnicodeCMap))
+	if !reflect.DeepEqual(c.codeLengths, map[int]bool{2: true}) {
+		t.Errorf("codeLengths = %v, want 2 bytes", c.codeLengths)
+	}
+	want := map[int]string{
+		0x03: " ",
+		0x10: "fi",
+		0x24: "A", 0x25: "B", 0x26: "C",
+		0x30: "क", 0x31: "𝐀",
+	}
+	if !reflect.DeepEqual(c.mappings, want) {
+		t.Errorf("mappings = %q, want %q", c.mappings, want)
+	}
+}
+
+func TestFontDecode(t *testing.T) {
+	twoByte := &pdfFont{twoByte: true, toUnicode: parseCMap([]byte(toUnicodeCMap)), widths: map[int]float64{0x24: 600}, defaultWidth: 1000}
+	simple := &pdfFont{encoding: baseEncoding(""), widths: map[int]float64{'A': 722}, defaultWidth: 500}
+
+	tests := []struct {
+		name string
+		font *pdfFont
+		data []byte
+		want []glyph
+	}{
+		{
+			name: "two byte codes through ToUnicode",
+			font: twoByte,
+			data: []byte{0x00, 0x24, 0x00, 0x03, 0x00, 0x10},
+			want: []glyph{{text: "A", width: 600}, {text: " ", width: 1000}, {text: "fi", width: 1000}},
+		},
+		{
+			name: "unmapped code",
+			font: twoByte,
+			data: []byte{0x00, 0x99},
+			want: []glyph{{text: "", width: 1000}},
+		},
+		{
+			name: "truncated code",
+			font: twoByte,
+			data: []byte{0x00, 0x25, 0x00},
+			want: []glyph{{text: "B", width: 1000}, {text: "", width: 1000}},
+		},
+		{
+			name: "single byte codes",
+			font: simple,
+			data: []byte("A b"),
+			want: []glyph{{text: "A", width: 722}, {text: " ", width: 500, space: true}, {text: "b", width: 500}},
+		},
+		{
+			name: "WinAnsi quotes",
+			font: simple,
+			data: []byte{0x93, 0x94},
+			want: []glyph{{text: "“", width: 500}, {text: "”", width: 500}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.font.decode(tt.data); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decode(% x) = %+v, want %+v", tt.data, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGlyphNameToText(t *testing.T) {
+	tests := map[string]string{
+		"a":          "a",
+		"quoteright": "’",
+		"fi":         "fi",
+		"uni0915":    "क",
+		"u00E9":      "é",
+		"g123":       "",
+	}
+	for name, want := range tests {
+		if got := glyphNameToText(name); got != want {
+			t.Errorf("glyphNameToText(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestBaseEncoding(t *testing.T) {
+	tests := []struct {
+		encoding string
+		code     byte
+		want     string
+	}{
+		{"WinAnsiEncoding", 0xE9, "é"},
+		{"", 0x80, "€"},
+		{"MacRomanEncoding", 0x8E, "é"},
+		{"StandardEncoding", '\'', "’"},
+		{"WinAnsiEncoding", 0x0A, ""},
+	}
+	for _, tt := range tests {
+		if got := baseEncoding(tt.encoding)[tt.code]; got != tt.want {
+			t.Errorf("baseEncoding(%q)[%#x] = %q, want %q", tt.encoding, tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestLoadFont(t *testing.T) {
+	t.Run("differences and widths", func(t *testing.T) {
+		f := loadFont(nil, types.Dict{
+			"Type":     types.Name("Font"),
+			"Subtype":  types.Name("Type1"),
+			"BaseFont": types.Name("ABCDEF+Palatino"),
+			"Encoding": types.Dict{
+				"BaseEncoding": types.Name("WinAnsiEncoding"),
+				"Differences":  types.Array{types.Integer(65), types.Name("alpha"), types.Name("uni03B2"), types.Integer(100), types.Name("fi")},
+			},
+			"FirstChar": types.Integer(65),
+			"Widths":    types.Array{types.Integer(700), types.Float(650.5)},
+		})
+		if f.name != "ABCDEF+Palatino" || f.twoByte {
+			t.Errorf("font = %q, twoByte %v", f.name, f.twoByte)
+		}
+		// Names without a known text decode to nothing rather than to the base encoding
+		if got := []string{f.encoding['A'], f.encoding['B'], f.encoding['C'], f.encoding['d']}; !reflect.DeepEqual(got, []string{"", "β", "C", "fi"}) {
+			t.Errorf("encoding of A, B, C, d = %q", got)
+		}
+		if f.widths['A'] != 700 || f.widths['B'] != 650.5 {
+			t.Errorf("widths = %v", f.widths)
+		}
+	})
+
+	t.Run("Type0 with ToUnicode and CID widths", func(t *testing.T) {
+		f := loadFont(nil, types.Dict{
+			"Subtype":   types.Name("Type0"),
+			"ToUnicode": types.StreamDict{Dict: types.Dict{}, Content: []byte(toUnicodeCMap)},
+			"DescendantFonts": types.Array{types.Dict{
+				"DW": types.Integer(900),
+				"W":  types.Array{types.Integer(36), types.Array{types.Integer(610), types.Integer(620)}, types.Integer(48), types.Integer(49), types.Integer(400)},
+			}},
+		})
+		if !f.twoByte || f.toUnicode == nil {
+			t.Fatalf("font is not read as two byte with ToUnicode: %+v", f)
+		}
+		want := map[int]float64{36: 610, 37: 620, 48: 400, 49: 400}
+		if !reflect.DeepEqual(f.widths, want) || f.defaultWidth != 900 {
+			t.Errorf("widths = %v and default %v, want %v and 900", f.widths, f.defaultWidth, want)
+		}
+		if got := f.decode([]byte{0x00, 0x24, 0x00, 0x25}); got[0].text != "A" || got[0].width != 610 || got[1].width != 620 {
+			t.Errorf("decode = %+v", got)
+		}
+	})
+
+	t.Run("missing font", func(t *testing.T) {
+		f := loadFont(nil, nil)
+		if f.encoding['a'] != "a" || f.defaultWidth != 500 {
+			t.Errorf("missing font does not fall back to WinAnsi: %+v", f)
+		}
+	})
+}
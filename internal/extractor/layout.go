@@ -0,0 +1,63 @@
+package extractor
+
+import (
+	"sort"
+	"strings"
+)
+
+// Line is a line of text with its bounding box in points, measured from the
+// top-left corner of the page like pdftotext -bbox-layout does.
+type Line struct {
+	Text     string  `json:"text"`
+	XMin     float64 `json:"xMin"`
+	YMin     float64 `json:"yMin"`
+	XMax     float64 `json:"xMax"`
+	YMax     float64 `json:"yMax"`
+	FontSize float64 `json:"fontSize"`
+	FontName string  `json:"fontName,omitempty"`
+}
+
+// Page is the layout of a single page, with lines from the top of the page to the bottom.
+type Page struct {
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+	Lines  []Line  `json:"lines"`
+}
+
+// LayoutExtractor is implemented by extractors that can return the position
+// and font of every line of text.
+type LayoutExtractor interface {
+	ExtractLayout(pdfPath string) ([]Page, error)
+}
+
+// Text returns the text of the page, one line of text per line.
+func (p Page) Text() string {
+	var b strings.Builder
+	for _, l := range p.Lines {
+		b.WriteString(l.Text)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// BodyFontSize returns the font size used by most of the text on the page.
+func (p Page) BodyFontSize() float64 {
+	chars := map[float64]int{}
+	for _, l := range p.Lines {
+		// Round to half points so that sizes differing by rounding errors count together
+		size := float64(int(l.FontSize*2+0.5)) / 2
+		chars[size] += len(l.Text)
+	}
+	sizes := make([]float64, 0, len(chars))
+	for size := range chars {
+		sizes = append(sizes, size)
+	}
+	sort.Float64s(sizes)
+	body := 0.0
+	for _, size := range sizes {
+		if chars[size] > chars[body] {
+			body = size
+		}
+	}
+	return body
+}
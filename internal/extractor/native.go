@@ -0,0 +1,370 @@
+package extractor
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"pdf-extractor/internal/utils"
+	"sync"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// maxFormDepth limits how deeply nested form XObjects are followed.
+const maxFormDepth = 8
+
+// NativeExtractor extracts text in-process by interpreting page content streams with pdfcpu.
+type NativeExtractor struct {
+	Jobs int
+
+	mu      sync.Mutex
+	path    string
+	modTime time.Time
+	size    int64
+	ctx     *model.Context
+}
+
+func NewNativeExtractor(jobs int) *NativeExtractor {
+	api.DisableConfigDir()
+	return &NativeExtractor{Jobs: jobs}
+}
+
+func (e *NativeExtractor) Name() string {
+	return EngineNative
+}
+
+// context returns the parsed document, reusing it while the file is unchanged.
+func (e *NativeExtractor) context(pdfPath string) (*model.Context, error) {
+	info, err := os.Stat(pdfPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", pdfPath, err)
+	}
+	if e.ctx != nil && e.path == pdfPath && e.modTime.Equal(info.ModTime()) && e.size == info.Size() {
+		return e.ctx, nil
+	}
+	ctx, err := api.ReadContextFile(pdfPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", pdfPath, err)
+	}
+	e.path, e.modTime, e.size, e.ctx = pdfPath, info.ModTime(), info.Size(), ctx
+	return ctx, nil
+}
+
+func (e *NativeExtractor) PageCount(pdfPath string) (int, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	ctx, err := e.context(pdfPath)
+	if err != nil {
+		return 0, err
+	}
+	return ctx.PageCount, nil
+}
+
+func (e *NativeExtractor) ExtractPage(pdfPath string, page int) (string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	ctx, err := e.context(pdfPath)
+	if err != nil {
+		return "", err
+	}
+	layout, err := pageLayout(ctx, page)
+	if err != nil {
+		return "", fmt.Errorf("failed to extract text from page %d: %v", page, err)
+	}
+	return layout.Text(), nil
+}
+
+func (e *NativeExtractor) ExtractPages(pdfPath string) ([]string, error) {
+	layouts, err := e.ExtractLayout(pdfPath)
+	if err != nil {
+		return nil, err
+	}
+	pages := make([]string, len(layouts))
+	for i, layout := range layouts {
+		pages[i] = layout.Text()
+	}
+	return pages, nil
+}
+
+func (e *NativeExtractor) ExtractLayout(pdfPath string) ([]Page, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	ctx, err := e.context(pdfPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// pdfcpu contexts are not safe for concurrent use, so every additional
+	// worker parses its own copy of the document and hands it back when done
+	contexts := make(chan *model.Context, max(e.Jobs, 1))
+	contexts <- ctx
+
+	pages := make([]Page, ctx.PageCount)
+	err = utils.RunParallel(e.Jobs, ctx.PageCount, func(_ context.Context, i int) error {
+		var pageCtx *model.Context
+		select {
+		case pageCtx = <-contexts:
+		default:
+			parsed, err := api.ReadContextFile(pdfPath)
+			if err != nil {
+				return fmt.Errorf("failed to parse %s: %v", pdfPath, err)
+			}
+			pageCtx = parsed
+		}
+		defer func() { contexts <- pageCtx }()
+
+		layout, err := pageLayout(pageCtx, i+1)
+		if err != nil {
+			return fmt.Errorf("failed to extract text from page %d: %v", i+1, err)
+		}
+		pages[i] = layout
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pages, nil
+}
+
+// pageLayout interprets the content stream of a page and returns the lines of text it shows.
+func pageLayout(ctx *model.Context, page int) (Page, error) {
+	d, _, inherited, err := ctx.PageDict(page, true)
+	if err != nil {
+		return Page{}, err
+	}
+	content, err := ctx.PageContent(d)
+	if err != nil && err != model.ErrNoContent {
+		return Page{}, err
+	}
+	var resources types.Dict
+	mediaBox := types.RectForFormat("A4")
+	if inherited != nil {
+		resources = inherited.Resources
+		if inherited.MediaBox != nil {
+			mediaBox = inherited.MediaBox
+		}
+	}
+	in := &interpreter{xRefTable: ctx.XRefTable, fonts: map[string]*pdfFont{}}
+	in.run(content, resources, identity, 0)
+
+	layout := Page{Width: mediaBox.Width(), Height: mediaBox.Height()}
+	for _, l := range groupLines(in.spans) {
+		layout.Lines = append(layout.Lines, l.layout(mediaBox))
+	}
+	return layout, nil
+}
+
+// textState holds the text parameters that persist across BT/ET blocks.
+type textState struct {
+	font        *pdfFont
+	fontSize    float64
+	charSpacing float64
+	wordSpacing float64
+	scale       float64
+	leading     float64
+	rise        float64
+}
+
+type interpreter struct {
+	xRefTable *model.XRefTable
+	fonts     map[string]*pdfFont
+	spans     []textSpan
+}
+
+func (in *interpreter) font(resources types.Dict, name string) *pdfFont {
+	fontDict := resources.DictEntry("Font")
+	key := fmt.Sprintf("%p/%s", fontDict, name)
+	if f, ok := in.fonts[key]; ok {
+		return f
+	}
+	var f *pdfFont
+	if o, found := fontDict.Find(name); found {
+		f = loadFont(in.xRefTable, o)
+	} else {
+		f = loadFont(in.xRefTable, nil)
+	}
+	in.fonts[key] = f
+	return f
+}
+
+func (in *interpreter) run(content []byte, resources types.Dict, ctm matrix, depth int) {
+	var (
+		stack      []operand
+		gstack     []matrix
+		gtext      []textState
+		state      = textState{scale: 1}
+		tm, tlm    = identity, identity
+		lexer      = &contentLexer{data: content}
+		num        = func(i int) float64 { return stack[len(stack)-i].num }
+		hasOperand = func(n int) bool { return len(stack) >= n }
+	)
+
+	show := func(s []byte) {
+		if state.font == nil {
+			state.font = loadFont(in.xRefTable, nil)
+		}
+		trm := matrix{state.fontSize * state.scale, 0, 0, state.fontSize, 0, state.rise}.multiply(tm).multiply(ctm)
+		x, y := trm[4], trm[5]
+		size := math.Hypot(trm[2], trm[3])
+		var text []rune
+		advance := 0.0
+		for _, g := range state.font.decode(s) {
+			text = append(text, []rune(g.text)...)
+			tx := (g.width/1000*state.fontSize + state.charSpacing) * state.scale
+			if g.space {
+				tx += state.wordSpacing * state.scale
+			}
+			advance += tx
+		}
+		start := tm
+		tm = matrix{1, 0, 0, 1, advance, 0}.multiply(tm)
+		endX, _ := tm.multiply(ctm).apply(0, 0)
+		startX, _ := start.multiply(ctm).apply(0, 0)
+		in.spans = append(in.spans, textSpan{
+			x:        x,
+			y:        y,
+			width:    math.Abs(endX - startX),
+			size:     size,
+			fontName: state.font.name,
+			text:     string(text),
+		})
+	}
+	nextLine := func(tx, ty float64) {
+		tlm = matrix{1, 0, 0, 1, tx, ty}.multiply(tlm)
+		tm = tlm
+	}
+
+	for {
+		op, operator, isOp, ok := lexer.next()
+		if !ok {
+			return
+		}
+		if !isOp {
+			stack = append(stack, op)
+			continue
+		}
+		switch operator {
+		case "q":
+			gstack = append(gstack, ctm)
+			gtext = append(gtext, state)
+		case "Q":
+			if n := len(gstack); n > 0 {
+				ctm, gstack = gstack[n-1], gstack[:n-1]
+				state, gtext = gtext[n-1], gtext[:n-1]
+			}
+		case "cm":
+			if hasOperand(6) {
+				ctm = matrix{num(6), num(5), num(4), num(3), num(2), num(1)}.multiply(ctm)
+			}
+		case "BT":
+			tm, tlm = identity, identity
+		case "Tf":
+			if hasOperand(2) && stack[len(stack)-2].kind == '/' {
+				state.font = in.font(resources, stack[len(stack)-2].name)
+				state.fontSize = num(1)
+			}
+		case "Tc":
+			if hasOperand(1) {
+				state.charSpacing = num(1)
+			}
+		case "Tw":
+			if hasOperand(1) {
+				state.wordSpacing = num(1)
+			}
+		case "Tz":
+			if hasOperand(1) {
+				state.scale = num(1) / 100
+			}
+		case "TL":
+			if hasOperand(1) {
+				state.leading = num(1)
+			}
+		case "Ts":
+			if hasOperand(1) {
+				state.rise = num(1)
+			}
+		case "Td":
+			if hasOperand(2) {
+				nextLine(num(2), num(1))
+			}
+		case "TD":
+			if hasOperand(2) {
+				state.leading = -num(1)
+				nextLine(num(2), num(1))
+			}
+		case "Tm":
+			if hasOperand(6) {
+				tlm = matrix{num(6), num(5), num(4), num(3), num(2), num(1)}
+				tm = tlm
+			}
+		case "T*":
+			nextLine(0, -state.leading)
+		case "Tj":
+			if hasOperand(1) {
+				show(stack[len(stack)-1].str)
+			}
+		case "'":
+			nextLine(0, -state.leading)
+			if hasOperand(1) {
+				show(stack[len(stack)-1].str)
+			}
+		case "\"":
+			if hasOperand(3) {
+				state.wordSpacing, state.charSpacing = num(3), num(2)
+				nextLine(0, -state.leading)
+				show(stack[len(stack)-1].str)
+			}
+		case "TJ":
+			if hasOperand(1) {
+				for _, v := range stack[len(stack)-1].values {
+					if v.kind == 'n' {
+						tm = matrix{1, 0, 0, 1, -v.num / 1000 * state.fontSize * state.scale, 0}.multiply(tm)
+						continue
+					}
+					show(v.str)
+				}
+			}
+		case "BI":
+			lexer.skipInlineImage()
+		case "Do":
+			if hasOperand(1) && depth < maxFormDepth {
+				in.form(resources, stack[len(stack)-1].name, ctm, depth)
+			}
+		}
+		stack = stack[:0]
+	}
+}
+
+// form interprets a form XObject with its own resources and matrix.
+func (in *interpreter) form(resources types.Dict, name string, ctm matrix, depth int) {
+	o, found := resources.DictEntry("XObject").Find(name)
+	if !found {
+		return
+	}
+	sd, _, err := in.xRefTable.DereferenceStreamDict(o)
+	if err != nil || sd == nil {
+		return
+	}
+	if st := sd.Subtype(); st == nil || *st != "Form" {
+		return
+	}
+	if err := sd.Decode(); err != nil {
+		return
+	}
+	formResources := resources
+	if r, err := in.xRefTable.DereferenceDict(sd.Dict["Resources"]); err == nil && r != nil {
+		formResources = r
+	}
+	if a, err := in.xRefTable.DereferenceArray(sd.Dict["Matrix"]); err == nil && len(a) == 6 {
+		var m matrix
+		for i, o := range a {
+			m[i], _ = in.xRefTable.DereferenceNumber(o)
+		}
+		ctm = m.multiply(ctm)
+	}
+	in.run(sd.Content, formResources, ctm, depth+1)
+}
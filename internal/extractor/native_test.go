@@ -0,0 +1,145 @@
+package extractor
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// run interprets a content stream with a Helvetica font as F1 and returns the lines it shows.
+func run(content string, xObjects types.Dict) []Line {
+	resources := types.Dict{
+		"Font": types.Dict{
+			"F1": types.Dict{"Subtype": types.Name("Type1"), "BaseFont": types.Name("Helvetica")},
+			"F2": types.Dict{"Subtype": types.Name("Type1"), "BaseFont": types.Name("ABCDEF+Helvetica-Bold")},
+		},
+		"XObject": xObjects,
+	}
+	in := &interpreter{fonts: map[string]*pdfFont{}}
+	in.run([]byte(content), resources, identity, 0)
+	mediaBox := types.NewRectangle(0, 0, 612, 792)
+	var lines []Line
+	for _, l := range groupLines(in.spans) {
+		lines = append(lines, l.layout(mediaBox))
+	}
+	return lines
+}
+
+func texts(lines []Line) []string {
+	var texts []string
+	for _, l := range lines {
+		texts = append(texts, l.Text)
+	}
+	return texts
+}
+
+func TestInterpreterText(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "Td and Tj",
+			content: "BT /F1 10 Tf 72 700 Td (First line) Tj 0 -12 Td (Second line) Tj ET",
+			want:    []string{"First line", "Second line"},
+		},
+		{
+			name:    "leading with T* and quotes",
+			content: "BT /F1 10 Tf 12 TL 72 700 Td (one) Tj T* (two) Tj (three) ' 1 0 (four) \" ET",
+			want:    []string{"one", "two", "three", "four"},
+		},
+		{
+			name:    "TD sets the leading",
+			content: "BT /F1 10 Tf 72 700 Td 0 -14 TD (a) Tj T* (b) Tj ET",
+			want:    []string{"a", "b"},
+		},
+		{
+			name:    "TJ kerning and word gaps",
+			content: "BT /F1 10 Tf 72 700 Td [(W) 80 (ater) -600 (Management)] TJ ET",
+			want:    []string{"Water Management"},
+		},
+		{
+			name:    "Tm positions the text",
+			content: "BT /F1 10 Tf 1 0 0 1 72 100 Tm (bottom) Tj 1 0 0 1 72 700 Tm (top) Tj ET",
+			want:    []string{"top", "bottom"},
+		},
+		{
+			name:    "text on a baseline split into spans",
+			content: "BT /F1 10 Tf 72 700 Td (Notes on) Tj ( Water) Tj ET",
+			want:    []string{"Notes on Water"},
+		},
+		{
+			name:    "q and Q restore the matrix",
+			content: "q 1 0 0 1 0 -300 cm BT /F1 10 Tf 72 700 Td (moved) Tj ET Q BT /F1 10 Tf 72 650 Td (kept) Tj ET",
+			want:    []string{"kept", "moved"},
+		},
+		{
+			name:    "inline images are skipped",
+			content: "BI /W 1 /H 1 /BPC 8 /CS /G ID (Tj) EI BT /F1 10 Tf 72 700 Td (after) Tj ET",
+			want:    []string{"after"},
+		},
+		{
+			name:    "operators without operands",
+			content: "BT Tf Td Tj TJ (x) ET",
+			want:    nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := texts(run(tt.content, nil)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lines = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInterpreterLayout(t *testing.T) {
+	lines := run("BT /F2 20 Tf 72 700 Td (Title) Tj /F1 10 Tf 0 -30 Td (Body) Tj ET", nil)
+	if len(lines) != 2 {
+		t.Fatalf("lines = %+v, want 2", lines)
+	}
+	title, body := lines[0], lines[1]
+	if title.FontSize != 20 || title.FontName != "Helvetica-Bold" || body.FontSize != 10 || body.FontName != "Helvetica" {
+		t.Errorf("fonts = %v %q and %v %q", title.FontSize, title.FontName, body.FontSize, body.FontName)
+	}
+	// The baseline at y=700 is 92 points below the top of the page
+	if title.XMin != 72 || title.YMin != 92-20*0.8 || title.YMax != 92+20*0.2 {
+		t.Errorf("title box = %+v", title)
+	}
+	// Five glyphs of the default width of half an em
+	if title.XMax != 72+5*0.5*20 {
+		t.Errorf("title ends at %v, want %v", title.XMax, 72+5*0.5*20)
+	}
+}
+
+func TestInterpreterScaling(t *testing.T) {
+	// A font size of 1 scaled by the text matrix is as large as a font size of 12
+	lines := run("BT /F1 1 Tf 12 0 0 12 72 700 Tm (Scaled) Tj ET", nil)
+	if len(lines) != 1 || lines[0].FontSize != 12 {
+		t.Errorf("lines = %+v, want one line set in 12 points", lines)
+	}
+}
+
+func TestInterpreterForms(t *testing.T) {
+	form := types.StreamDict{
+		Dict: types.Dict{
+			"Subtype": types.Name("Form"),
+			"Matrix":  types.Array{types.Integer(1), types.Integer(0), types.Integer(0), types.Integer(1), types.Integer(0), types.Integer(-100)},
+		},
+		Content: []byte("BT /F1 10 Tf 72 700 Td (In the form) Tj ET"),
+	}
+	image := types.StreamDict{Dict: types.Dict{"Subtype": types.Name("Image")}, Content: []byte("BT /F1 10 Tf (x) Tj ET")}
+	// A form drawing itself stops at the nesting limit
+	loop := types.StreamDict{Dict: types.Dict{"Subtype": types.Name("Form")}, Content: []byte("/Loop Do")}
+	xObjects := types.Dict{"Fm1": form, "Im1": image, "Loop": loop}
+
+	lines := run("BT /F1 10 Tf 72 700 Td (Page) Tj ET /Fm1 Do /Im1 Do /Loop Do /Missing Do", xObjects)
+	if got := texts(lines); !reflect.DeepEqual(got, []string{"Page", "In the form"}) {
+		t.Errorf("lines = %q", got)
+	}
+	if len(lines) == 2 && lines[1].YMin-lines[0].YMin != 100 {
+		t.Errorf("the form matrix did not move its text down 100 points: %+v", lines)
+	}
+}
@@ -0,0 +1,279 @@
+package extractor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"pdf-extractor/internal/tools"
+	"pdf-extractor/internal/utils"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	OCRAuto   = "auto"
+	OCRAlways = "always"
+	OCRNever  = "never"
+)
+
+const (
+	// ocrResolution is the resolution in DPI pages are rendered at before OCR.
+	ocrResolution = 300
+	// ocrMinConfidence is the confidence out of 100 below which a recognized word, most often
+	// a speck of the scan, is left out
+	ocrMinConfidence = 20
+)
+
+// OCRExtractor wraps a TextExtractor and recognizes the text of scanned pages
+// by rendering them with pdftoppm and running tesseract on the images.
+type OCRExtractor struct {
+	next TextExtractor
+	Mode string
+	Jobs int
+
+	toolsOnce  sync.Once
+	toolsErr   error
+	warnOnce   sync.Once
+	mu         sync.Mutex
+	recognized map[string]Page
+}
+
+// NewOCRExtractor wraps next for the given --ocr mode, or returns next unchanged for never.
+func NewOCRExtractor(next TextExtractor, mode string, jobs int) (TextExtractor, error) {
+	switch mode {
+	case OCRNever:
+		return next, nil
+	case OCRAuto, OCRAlways:
+		return &OCRExtractor{next: next, Mode: mode, Jobs: jobs}, nil
+	}
+	return nil, fmt.Errorf("unknown OCR mode '%s': expected '%s', '%s' or '%s'", mode, OCRAuto, OCRAlways, OCRNever)
+}
+
+// Name includes the OCR mode so that cached text with and without OCR is kept apart.
+// Without the OCR tools auto mode extracts the same text as the wrapped engine.
+func (e *OCRExtractor) Name() string {
+	if e.Mode == OCRAuto && e.checkTools() != nil {
+		return e.next.Name()
+	}
+	return fmt.Sprintf("%s+ocr-%s", e.next.Name(), e.Mode)
+}
+
+// checkTools looks up the OCR tools once.
+func (e *OCRExtractor) checkTools() error {
+	e.toolsOnce.Do(func() {
+		e.toolsErr = tools.Check([]string{"pdftoppm", "tesseract"})
+	})
+	return e.toolsErr
+}
+
+// RequiredTools only includes the OCR tools for --ocr=always; in auto mode
+// scanned pages are left empty with a warning when they are missing.
+func (e *OCRExtractor) RequiredTools() []string {
+	required := tools.Required(e.next)
+	if e.Mode == OCRAlways {
+		required = append(required, "pdftoppm", "tesseract")
+	}
+	return required
+}
+
+func (e *OCRExtractor) PageCount(pdfPath string) (int, error) {
+	return e.next.PageCount(pdfPath)
+}
+
+func (e *OCRExtractor) ExtractPage(pdfPath string, page int) (string, error) {
+	content, err := e.next.ExtractPage(pdfPath, page)
+	if err != nil {
+		return "", err
+	}
+	if len(e.scannedPages([]string{content})) == 0 {
+		return content, nil
+	}
+	layout, err := e.recognize(context.Background(), pdfPath, page)
+	if err != nil {
+		return "", err
+	}
+	return layout.Text(), nil
+}
+
+func (e *OCRExtractor) ExtractPages(pdfPath string) ([]string, error) {
+	pages, err := e.next.ExtractPages(pdfPath)
+	if err != nil {
+		return nil, err
+	}
+	scanned := e.scannedPages(pages)
+	err = utils.RunParallel(e.Jobs, len(scanned), func(ctx context.Context, i int) error {
+		layout, err := e.recognize(ctx, pdfPath, scanned[i])
+		if err != nil {
+			return err
+		}
+		pages[scanned[i]-1] = layout.Text()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pages, nil
+}
+
+func (e *OCRExtractor) ExtractLayout(pdfPath string) ([]Page, error) {
+	l, ok := e.next.(LayoutExtractor)
+	if !ok {
+		return nil, fmt.Errorf("text engine '%s' does not report page layout", e.next.Name())
+	}
+	layouts, err := l.ExtractLayout(pdfPath)
+	if err != nil {
+		return nil, err
+	}
+	pages := make([]string, len(layouts))
+	for i, layout := range layouts {
+		pages[i] = layout.Text()
+	}
+	scanned := e.scannedPages(pages)
+	err = utils.RunParallel(e.Jobs, len(scanned), func(ctx context.Context, i int) error {
+		layout, err := e.recognize(ctx, pdfPath, scanned[i])
+		if err != nil {
+			return err
+		}
+		layouts[scanned[i]-1] = layout
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return layouts, nil
+}
+
+// needsOCR reports whether text extracted from a page should be replaced by OCR.
+func (e *OCRExtractor) needsOCR(content string) bool {
+	return e.Mode == OCRAlways || strings.TrimSpace(content) == ""
+}
+
+// scannedPages returns the 1-indexed pages to run OCR on, or none when the
+// OCR tools are missing in auto mode.
+func (e *OCRExtractor) scannedPages(pages []string) []int {
+	var scanned []int
+	for i, content := range pages {
+		if e.needsOCR(content) {
+			scanned = append(scanned, i+1)
+		}
+	}
+	if len(scanned) > 0 && e.Mode == OCRAuto {
+		if err := e.checkTools(); err != nil {
+			e.warnOnce.Do(func() {
+				logrus.Warnf("%d pages have no text layer and were left empty: %v", len(scanned), err)
+			})
+			return nil
+		}
+	}
+	return scanned
+}
+
+// recognize returns the text tesseract finds on a page, running OCR only once per page.
+func (e *OCRExtractor) recognize(ctx context.Context, pdfPath string, page int) (Page, error) {
+	key := fmt.Sprintf("%s#%d", pdfPath, page)
+	e.mu.Lock()
+	layout, ok := e.recognized[key]
+	e.mu.Unlock()
+	if ok {
+		return layout, nil
+	}
+
+	logrus.Infof("Running OCR on page %d", page)
+	layout, err := ocrPage(ctx, pdfPath, page)
+	if err != nil {
+		return Page{}, err
+	}
+	e.mu.Lock()
+	if e.recognized == nil {
+		e.recognized = map[string]Page{}
+	}
+	e.recognized[key] = layout
+	e.mu.Unlock()
+	return layout, nil
+}
+
+// ocrPage renders a page to an image with pdftoppm and runs tesseract on it.
+func ocrPage(ctx context.Context, pdfPath string, page int) (Page, error) {
+	dir, err := os.MkdirTemp("", "pdf-extractor-ocr")
+	if err != nil {
+		return Page{}, fmt.Errorf("failed to create temporary directory for OCR: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	prefix := filepath.Join(dir, "page")
+	cmd := exec.CommandContext(ctx, "pdftoppm", "-r", strconv.Itoa(ocrResolution), "-f", strconv.Itoa(page), "-l", strconv.Itoa(page), "-singlefile", "-png", pdfPath, prefix)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return Page{}, fmt.Errorf("failed to render page %d using pdftoppm: %v: %s", page, err, strings.TrimSpace(string(output)))
+	}
+
+	cmd = exec.CommandContext(ctx, "tesseract", prefix+".png", "stdout", "tsv")
+	output, err := cmd.Output()
+	if err != nil {
+		return Page{}, fmt.Errorf("failed to recognize text on page %d using tesseract: %v", page, err)
+	}
+	layout := parseTesseractTSV(string(output))
+	logrus.Debugf("Recognized %d lines on page %d", len(layout.Lines), page)
+	return layout, nil
+}
+
+// parseTesseractTSV groups the words of tesseract's TSV output into lines, converting
+// pixel positions into points. Words recognized with a confidence below ocrMinConfidence
+// are left out.
+func parseTesseractTSV(output string) Page {
+	scale := 72.0 / ocrResolution
+	var page Page
+	lines := map[string]*Line{}
+	var order []string
+	for i, row := range strings.Split(output, "\n") {
+		// The first row holds the column names
+		record := strings.Split(strings.TrimRight(row, "\r"), "\t")
+		if i == 0 {
+			continue
+		}
+		if len(record) < 12 {
+			continue
+		}
+		var v [11]float64
+		for j := range v {
+			v[j], _ = strconv.ParseFloat(record[j], 64)
+		}
+		level, left, top, width, height := int(v[0]), v[6]*scale, v[7]*scale, v[8]*scale, v[9]*scale
+		if level == 1 {
+			page.Width, page.Height = width, height
+			continue
+		}
+		text := strings.TrimSpace(record[11])
+		if level != 5 || text == "" || v[10] < ocrMinConfidence {
+			continue
+		}
+
+		// Words are identified by page, block, paragraph and line number
+		key := strings.Join(record[1:5], "/")
+		l, ok := lines[key]
+		if !ok {
+			l = &Line{XMin: left, YMin: top, XMax: left + width, YMax: top + height}
+			lines[key] = l
+			order = append(order, key)
+		} else {
+			l.Text += " "
+		}
+		l.Text += text
+		l.XMin, l.YMin = min(l.XMin, left), min(l.YMin, top)
+		l.XMax, l.YMax = max(l.XMax, left+width), max(l.YMax, top+height)
+	}
+	for _, key := range order {
+		l := lines[key]
+		l.FontSize = l.YMax - l.YMin
+		page.Lines = append(page.Lines, *l)
+	}
+	sort.SliceStable(page.Lines, func(a, b int) bool {
+		return page.Lines[a].YMin < page.Lines[b].YMin
+	})
+	return page
+}
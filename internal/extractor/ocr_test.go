@@ -0,0 +1,92 @@
+package extractor
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// tesseractTSV is tesseract's TSV output for a page rendered at 300 DPI with a heading, a
+// line of two words, a word from another block on the same line, and a speck read as a word.
+var tesseractTSV = strings.Join([]string{
+	"level\tpage_num\tblock_num\tpar_num\tline_num\tword_num\tleft\ttop\twidth\theight\tconf\ttext",
+	"1\t1\t0\t0\t0\t0\t0\t0\t2550\t3300\t-1\t",
+	"2\t1\t1\t0\t0\t0\t300\t300\t900\t200\t-1\t",
+	"3\t1\t1\t1\t0\t0\t300\t300\t900\t200\t-1\t",
+	"4\t1\t1\t1\t1\t0\t300\t300\t900\t100\t-1\t",
+	"5\t1\t1\t1\t1\t1\t300\t300\t900\t100\t96.5\tRivers",
+	"4\t1\t1\t1\t2\t0\t300\t450\t600\t50\t-1\t",
+	"5\t1\t1\t1\t2\t1\t300\t450\t250\t50\t91\tA.",
+	"5\t1\t1\t1\t2\t2\t600\t452\t300\t50\t89\tKumar",
+	"5\t1\t2\t1\t1\t1\t1500\t450\t300\t50\t90\tpage",
+	"5\t1\t3\t1\t1\t1\t2000\t3000\t20\t20\t4\t~",
+	"5\t1\t3\t1\t1\t2\t2100\t3000\t20\t20\t95\t ",
+	"",
+}, "\r\n")
+
+func TestParseTesseractTSV(t *testing.T) {
+	page := parseTesseractTSV(tesseractTSV)
+	if page.Width != 612 || page.Height != 792 {
+		t.Errorf("page size = %v x %v, want 612 x 792", page.Width, page.Height)
+	}
+	if got, want := texts(page.Lines), []string{"Rivers", "A. Kumar", "page"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("lines = %q, want %q", got, want)
+	}
+	// 300 pixels at 300 DPI are 72 points
+	scale := 72.0 / ocrResolution
+	heading, author := page.Lines[0], page.Lines[1]
+	if heading.XMin != 72 || heading.YMin != 72 || heading.XMax != 288 || heading.FontSize != 24 {
+		t.Errorf("heading = %+v", heading)
+	}
+	if author.XMin != 72 || author.XMax != 216 || author.YMin != 108 || author.YMax != 502*scale {
+		t.Errorf("author = %+v", author)
+	}
+}
+
+func TestParseTesseractTSVEmpty(t *testing.T) {
+	if page := parseTesseractTSV(""); len(page.Lines) != 0 {
+		t.Errorf("lines = %+v, want none", page.Lines)
+	}
+}
+
+// namedExtractor is a TextExtractor that only has a name.
+type namedExtractor struct {
+	TextExtractor
+	name string
+}
+
+func (e namedExtractor) Name() string { return e.name }
+
+func TestOCRExtractorName(t *testing.T) {
+	tests := []struct {
+		name         string
+		mode         string
+		toolsMissing bool
+		want         string
+	}{
+		{name: "auto", mode: OCRAuto, want: "native+ocr-auto"},
+		{name: "always", mode: OCRAlways, want: "native+ocr-always"},
+		{name: "auto without the tools", mode: OCRAuto, toolsMissing: true, want: "native"},
+		{name: "always without the tools", mode: OCRAlways, toolsMissing: true, want: "native+ocr-always"},
+		{name: "never", mode: OCRNever, want: "native"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ext, err := NewOCRExtractor(namedExtractor{name: EngineNative}, tt.mode, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ocr, ok := ext.(*OCRExtractor); ok {
+				// Decide whether the tools are found, whatever is installed
+				ocr.toolsOnce.Do(func() {})
+				if tt.toolsMissing {
+					ocr.toolsErr = errors.New("tesseract not found")
+				}
+			}
+			if got := ext.Name(); got != tt.want {
+				t.Errorf("Name() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
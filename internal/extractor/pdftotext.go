@@ -0,0 +1,187 @@
+package extractor
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"os/exec"
+	"pdf-extractor/internal/utils"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// PdftotextExtractor extracts text by shelling out to poppler's pdfinfo and pdftotext.
+type PdftotextExtractor struct {
+	Jobs int
+}
+
+func (e *PdftotextExtractor) Name() string {
+	return EnginePdftotext
+}
+
+func (e *PdftotextExtractor) RequiredTools() []string {
+	return []string{"pdfinfo", "pdftotext"}
+}
+
+func (e *PdftotextExtractor) PageCount(pdfPath string) (int, error) {
+	// Run the pdfinfo command to get the total number of pages
+	logrus.Debugf("PDF Path: %s", pdfPath)
+	cmd := exec.Command("pdfinfo", pdfPath)
+	output, err := cmd.Output()
+	if err != nil {
+		return 0, fmt.Errorf("failed to run pdfinfo: %v", err)
+	}
+
+	// Parse the output to find the "Pages" line
+	var totalPages int
+	lines := string(output)
+	for _, line := range strings.Split(lines, "\n") {
+		if strings.HasPrefix(line, "Pages:") {
+			_, err := fmt.Sscanf(line, "Pages: %d", &totalPages)
+			if err != nil {
+				return 0, fmt.Errorf("failed to parse page count: %v", err)
+			}
+			break
+		}
+	}
+
+	return totalPages, nil
+}
+
+func (e *PdftotextExtractor) ExtractPage(pdfPath string, page int) (string, error) {
+	// Run the pdftotext command for a specific page in layout mode, writing to stdout
+	cmd := exec.Command("pdftotext", "-layout", "-f", fmt.Sprintf("%d", page), "-l", fmt.Sprintf("%d", page), pdfPath, "-")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to extract text using pdftotext: %v", err)
+	}
+	return string(output), nil
+}
+
+func (e *PdftotextExtractor) ExtractPages(pdfPath string) ([]string, error) {
+	totalPages, err := e.PageCount(pdfPath)
+	if err != nil {
+		return nil, err
+	}
+	pages := make([]string, totalPages)
+	err = e.forChunks(totalPages, func(ctx context.Context, first, last int) error {
+		chunk, err := e.extractRange(ctx, pdfPath, first, last)
+		if err != nil {
+			return err
+		}
+		copy(pages[first-1:], chunk)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pages, nil
+}
+
+func (e *PdftotextExtractor) ExtractLayout(pdfPath string) ([]Page, error) {
+	totalPages, err := e.PageCount(pdfPath)
+	if err != nil {
+		return nil, err
+	}
+	pages := make([]Page, totalPages)
+	err = e.forChunks(totalPages, func(ctx context.Context, first, last int) error {
+		chunk, err := e.layoutRange(ctx, pdfPath, first, last)
+		if err != nil {
+			return err
+		}
+		copy(pages[first-1:], chunk)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pages, nil
+}
+
+// forChunks splits the document into one contiguous chunk of pages per worker and runs fn on every chunk.
+func (e *PdftotextExtractor) forChunks(totalPages int, fn func(ctx context.Context, first, last int) error) error {
+	jobs := min(max(e.Jobs, 1), max(totalPages, 1))
+	chunkSize := (totalPages + jobs - 1) / jobs
+	return utils.RunParallel(jobs, jobs, func(ctx context.Context, i int) error {
+		first := i*chunkSize + 1
+		last := min(first+chunkSize-1, totalPages)
+		if first > last {
+			return nil
+		}
+		return fn(ctx, first, last)
+	})
+}
+
+// extractRange extracts pages first..last with a single pdftotext run.
+func (e *PdftotextExtractor) extractRange(ctx context.Context, pdfPath string, first, last int) ([]string, error) {
+	// pdftotext terminates every page with a form feed, so one run yields the whole range
+	cmd := exec.CommandContext(ctx, "pdftotext", "-layout", "-f", fmt.Sprintf("%d", first), "-l", fmt.Sprintf("%d", last), pdfPath, "-")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract text of pages %d-%d using pdftotext: %v", first, last, err)
+	}
+	pages := strings.Split(string(output), "\f")
+	if len(pages) < last-first+1 {
+		return nil, fmt.Errorf("pdftotext returned %d pages for pages %d-%d", len(pages), first, last)
+	}
+	return pages[:last-first+1], nil
+}
+
+// bboxDocument is the XHTML written by pdftotext -bbox-layout.
+type bboxDocument struct {
+	Pages []struct {
+		Width  float64 `xml:"width,attr"`
+		Height float64 `xml:"height,attr"`
+		Lines  []struct {
+			XMin  float64  `xml:"xMin,attr"`
+			YMin  float64  `xml:"yMin,attr"`
+			XMax  float64  `xml:"xMax,attr"`
+			YMax  float64  `xml:"yMax,attr"`
+			Words []string `xml:"word"`
+		} `xml:"flow>block>line"`
+	} `xml:"body>doc>page"`
+}
+
+// layoutRange returns the layout of pages first..last with a single pdftotext -bbox-layout run.
+func (e *PdftotextExtractor) layoutRange(ctx context.Context, pdfPath string, first, last int) ([]Page, error) {
+	cmd := exec.CommandContext(ctx, "pdftotext", "-bbox-layout", "-f", fmt.Sprintf("%d", first), "-l", fmt.Sprintf("%d", last), pdfPath, "-")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract layout of pages %d-%d using pdftotext: %v", first, last, err)
+	}
+
+	var doc bboxDocument
+	decoder := xml.NewDecoder(bytes.NewReader(output))
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to parse pdftotext layout of pages %d-%d: %v", first, last, err)
+	}
+	if len(doc.Pages) < last-first+1 {
+		return nil, fmt.Errorf("pdftotext returned %d pages for pages %d-%d", len(doc.Pages), first, last)
+	}
+
+	pages := make([]Page, last-first+1)
+	for i := range pages {
+		p := doc.Pages[i]
+		pages[i] = Page{Width: p.Width, Height: p.Height}
+		for _, l := range p.Lines {
+			// pdftotext does not report fonts, so the height of the line stands in for its font size
+			pages[i].Lines = append(pages[i].Lines, Line{
+				Text:     strings.Join(l.Words, " "),
+				XMin:     l.XMin,
+				YMin:     l.YMin,
+				XMax:     l.XMax,
+				YMax:     l.YMax,
+				FontSize: l.YMax - l.YMin,
+			})
+		}
+		sort.SliceStable(pages[i].Lines, func(a, b int) bool {
+			return pages[i].Lines[a].YMin < pages[i].Lines[b].YMin
+		})
+	}
+	return pages, nil
+}
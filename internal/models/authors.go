@@ -0,0 +1,102 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// authorSeparatorRegex matches what separates the names in a list of authors
+	authorSeparatorRegex = regexp.MustCompile(`\s*(?:[,;&]\s*(?i:and\s)?|\s(?i:and)\s*[,;]?\s)\s*`)
+	// nameSuffixRegex matches what follows a name after a comma without being another author
+	nameSuffixRegex = regexp.MustCompile(`^(?i:jr|sr|ii|iii|iv|phd|ph\.d|md)\.?$`)
+	// emailRegex matches an email address
+	emailRegex = regexp.MustCompile(`<?([\w.+-]+@[\w-]+(?:\.[\w-]+)+)>?`)
+	// parenthesisRegex matches an affiliation in parentheses after a name
+	parenthesisRegex = regexp.MustCompile(`\s*\(([^)]*)\)`)
+	// affiliationRegex matches words naming institutions
+	affiliationRegex = regexp.MustCompile(`(?i)\b(universit\w*|institut\w*|college|department|dept|school|faculty|cent(?:re|er)|laborator\w*|lab|academy|hospital|foundation|council|ministry|company|corporation|ltd|inc)\b`)
+)
+
+// ParseAuthors splits a line listing authors, such as "A. Kumar, B. Singh and C. Rao", on
+// commas, "and" and "&". An email or an affiliation in parentheses after a name belongs to
+// that author, and so does a part of the list naming an institution.
+func ParseAuthors(line string) []Author {
+	var authors []Author
+	for _, part := range splitAuthorList(line) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		last := len(authors) - 1
+		if last >= 0 && nameSuffixRegex.MatchString(part) {
+			authors[last].Name += ", " + part
+			continue
+		}
+
+		var author Author
+		if m := emailRegex.FindStringSubmatchIndex(part); m != nil {
+			author.Email = part[m[2]:m[3]]
+			part = strings.TrimSpace(part[:m[0]] + part[m[1]:])
+		}
+		if m := parenthesisRegex.FindStringSubmatchIndex(part); m != nil {
+			author.Affiliation = strings.TrimSpace(part[m[2]:m[3]])
+			part = strings.TrimSpace(part[:m[0]] + part[m[1]:])
+		}
+		author.Name = part
+
+		// A part with no name of its own, such as an email or an institution, describes the previous author
+		if last >= 0 && (author.Name == "" || IsAffiliation(author.Name)) {
+			if author.Email != "" && authors[last].Email == "" {
+				authors[last].Email = author.Email
+			}
+			if author.Name != "" && authors[last].Affiliation == "" {
+				authors[last].Affiliation = author.Name
+			}
+			continue
+		}
+		if author.Name != "" || author.Email != "" {
+			authors = append(authors, author)
+		}
+	}
+	return authors
+}
+
+// splitAuthorList splits a list of authors on its separators, except within parentheses and
+// within an institution, whose name may hold "and" or "&" as in "Department of Physics and
+// Astronomy". Only a comma or a semicolon ends an institution.
+func splitAuthorList(line string) []string {
+	var parts []string
+	start := 0
+	for _, m := range authorSeparatorRegex.FindAllStringIndex(line, -1) {
+		part := line[start:m[0]]
+		if strings.Count(part, "(") > strings.Count(part, ")") {
+			continue
+		}
+		if !strings.ContainsAny(line[m[0]:m[1]], ",;") && IsAffiliation(parenthesisRegex.ReplaceAllString(part, "")) {
+			continue
+		}
+		parts = append(parts, line[start:m[0]])
+		start = m[1]
+	}
+	return append(parts, line[start:])
+}
+
+// IsAffiliation reports whether a line names an institution, such as a university or a department.
+func IsAffiliation(line string) bool {
+	return affiliationRegex.MatchString(line) && !emailRegex.MatchString(line)
+}
+
+// IsEmailLine reports whether a line only holds email addresses.
+func IsEmailLine(line string) bool {
+	return emailRegex.MatchString(line) && strings.TrimSpace(authorSeparatorRegex.ReplaceAllString(emailRegex.ReplaceAllString(line, ""), "")) == ""
+}
+
+// Emails returns the email addresses in a line.
+func Emails(line string) []string {
+	var emails []string
+	for _, m := range emailRegex.FindAllStringSubmatch(line, -1) {
+		emails = append(emails, m[1])
+	}
+	return emails
+}
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAuthors(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want []Author
+	}{
+		{
+			name: "single author",
+			line: "A. Kumar",
+			want: []Author{{Name: "A. Kumar"}},
+		},
+		{
+			name: "commas and and",
+			line: "A. Kumar, B. Singh and C. Rao",
+			want: []Author{{Name: "A. Kumar"}, {Name: "B. Singh"}, {Name: "C. Rao"}},
+		},
+		{
+			name: "ampersand",
+			line: "A. Kumar & B. Singh",
+			want: []Author{{Name: "A. Kumar"}, {Name: "B. Singh"}},
+		},
+		{
+			name: "and after a comma",
+			line: "A. Kumar, B. Singh, and C. Rao",
+			want: []Author{{Name: "A. Kumar"}, {Name: "B. Singh"}, {Name: "C. Rao"}},
+		},
+		{
+			name: "and at the end of a wrapped line",
+			line: "B. Singh and, C. Rao",
+			want: []Author{{Name: "B. Singh"}, {Name: "C. Rao"}},
+		},
+		{
+			name: "name suffix",
+			line: "John Smith, Jr. and Jane Doe",
+			want: []Author{{Name: "John Smith, Jr."}, {Name: "Jane Doe"}},
+		},
+		{
+			name: "email after a name",
+			line: "A. Kumar <a.kumar@example.org>, B. Singh",
+			want: []Author{{Name: "A. Kumar", Email: "a.kumar@example.org"}, {Name: "B. Singh"}},
+		},
+		{
+			name: "affiliation in parentheses",
+			line: "A. Kumar (University of Delhi, India) and B. Singh",
+			want: []Author{{Name: "A. Kumar", Affiliation: "University of Delhi, India"}, {Name: "B. Singh"}},
+		},
+		{
+			name: "affiliation after a comma",
+			line: "Ravi Kumar, University of Delhi",
+			want: []Author{{Name: "Ravi Kumar", Affiliation: "University of Delhi"}},
+		},
+		{
+			name: "affiliation holding and",
+			line: "Ravi Kumar, Department of Physics and Astronomy",
+			want: []Author{{Name: "Ravi Kumar", Affiliation: "Department of Physics and Astronomy"}},
+		},
+		{
+			name: "affiliation holding an ampersand before the next author",
+			line: "Ravi Kumar, Dept. of Physics & Astronomy; B. Singh",
+			want: []Author{{Name: "Ravi Kumar", Affiliation: "Dept. of Physics & Astronomy"}, {Name: "B. Singh"}},
+		},
+		{
+			name: "empty",
+			line: " , ",
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseAuthors(tt.line); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseAuthors(%q) = %+v, want %+v", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsEmailLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want bool
+	}{
+		{"a.kumar@example.org", true},
+		{"a.kumar@example.org, b.singh@example.org", true},
+		{"A. Kumar <a.kumar@example.org>", false},
+		{"University of Delhi", false},
+	}
+	for _, tt := range tests {
+		if got := IsEmailLine(tt.line); got != tt.want {
+			t.Errorf("IsEmailLine(%q) = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
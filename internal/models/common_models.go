@@ -1,11 +1,129 @@
 package models
 
+import "strings"
+
 type Article struct {
-	Title  string
-	Author string
+	Title   string
+	Authors []Author `yaml:"authors,omitempty"`
+	// Number is the number of the entry in the contents as printed, such as "1.2" or "Part II"
+	Number string `yaml:"number,omitempty"`
+	// Page is the printed page number the article starts on, as listed in the contents
+	Page int `yaml:"page,omitempty"`
+	// PhysicalPage is the page of the PDF the article starts on, as read from the outline
+	PhysicalPage int `yaml:"physical_page,omitempty"`
+	// Level is the nesting depth of the article in the outline or the numbering of the contents, starting at 1
+	Level int `yaml:"level,omitempty"`
+	// Section is the title of the section the article is listed under, if any
+	Section string `yaml:"-"`
+
+	// The fields below are set by hand to correct what extract would detect
+	// Start is the page of the PDF the article starts on, used instead of searching for the title
+	Start int `yaml:"start,omitempty"`
+	// End is the last page of the PDF the article is extracted to
+	End int `yaml:"end,omitempty"`
+	// EndsWith is the text starting the page after the last page of the article
+	EndsWith string `yaml:"endsWith,omitempty"`
+	// Filename is the name of the PDF the article is written to, instead of one made from the title
+	Filename string `yaml:"filename,omitempty"`
+	// Skip leaves the article out of the extracted PDFs, while it still ends the article before it
+	Skip bool `yaml:"skip,omitempty"`
+}
+
+// FixedStart returns the page of the PDF the article is known to start on, from start or
+// from the outline, or 0 when it must be searched for.
+func (a Article) FixedStart() int {
+	if a.Start > 0 {
+		return a.Start
+	}
+	return a.PhysicalPage
+}
+
+// Author is an author of an article, with the institution and email printed with the name, if any.
+type Author struct {
+	Name        string `yaml:"name"`
+	Affiliation string `yaml:"affiliation,omitempty"`
+	Email       string `yaml:"email,omitempty"`
+}
+
+// articleFields has the fields of Article without its UnmarshalYAML method.
+type articleFields Article
+
+// article is an article as written in config.yaml, which may use the keys of older configs.
+type article struct {
+	articleFields `yaml:",inline"`
+	Author        string `yaml:"author"`
+	EndsWith      string `yaml:"ends_with"`
+}
+
+// UnmarshalYAML also reads the single author string that configs written before the
+// authors list was added have, and ends_with as another spelling of endsWith.
+func (a *Article) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var raw article
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+	*a = Article(raw.articleFields)
+	if len(a.Authors) == 0 && raw.Author != "" {
+		a.Authors = ParseAuthors(raw.Author)
+	}
+	if a.EndsWith == "" {
+		a.EndsWith = raw.EndsWith
+	}
+	return nil
+}
+
+// AuthorNames returns the names of the authors, separated by commas.
+func (a Article) AuthorNames() string {
+	names := make([]string, len(a.Authors))
+	for i, author := range a.Authors {
+		names[i] = author.Name
+	}
+	return strings.Join(names, ", ")
 }
 
 // Define the YAML structure
 type ArticlesConfig struct {
+	// ContentsKeywords are the headings the contents were found under, when not the default ones
+	ContentsKeywords []string `yaml:"contents_keywords,omitempty"`
+	// Articles are the articles listed outside any section, before the sections
+	Articles []Article `yaml:"articles,omitempty"`
+	Sections []Section `yaml:"sections,omitempty"`
+}
+
+// Section is a group of articles listed under a heading of the contents, such as "Book Reviews".
+type Section struct {
+	Title    string    `yaml:"title"`
 	Articles []Article `yaml:"articles"`
 }
+
+// NewArticlesConfig groups the articles by their section. Consecutive articles of the same
+// section share an entry of Sections, and articles without a section are kept in Articles.
+func NewArticlesConfig(articles []Article) ArticlesConfig {
+	var config ArticlesConfig
+	for _, article := range articles {
+		if article.Section == "" {
+			config.Articles = append(config.Articles, article)
+			continue
+		}
+		last := len(config.Sections) - 1
+		if last < 0 || config.Sections[last].Title != article.Section {
+			config.Sections = append(config.Sections, Section{Title: article.Section})
+			last++
+		}
+		config.Sections[last].Articles = append(config.Sections[last].Articles, article)
+	}
+	return config
+}
+
+// AllArticles returns the articles outside any section followed by those of every section,
+// in order, with their Section set.
+func (c ArticlesConfig) AllArticles() []Article {
+	articles := append([]Article{}, c.Articles...)
+	for _, section := range c.Sections {
+		for _, article := range section.Articles {
+			article.Section = section.Title
+			articles = append(articles, article)
+		}
+	}
+	return articles
+}
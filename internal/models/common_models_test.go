@@ -0,0 +1,103 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestArticleUnmarshalEndsWith(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want string
+	}{
+		{name: "endsWith", yaml: "title: Rivers\nendsWith: Lakes", want: "Lakes"},
+		{name: "ends_with", yaml: "title: Rivers\nends_with: Lakes", want: "Lakes"},
+		{name: "endsWith wins over ends_with", yaml: "title: Rivers\nendsWith: Lakes\nends_with: Seas", want: "Lakes"},
+		{name: "neither", yaml: "title: Rivers", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var article Article
+			if err := yaml.UnmarshalStrict([]byte(tt.yaml), &article); err != nil {
+				t.Fatalf("UnmarshalStrict() error = %v", err)
+			}
+			if article.EndsWith != tt.want {
+				t.Errorf("EndsWith = %q, want %q", article.EndsWith, tt.want)
+			}
+		})
+	}
+}
+
+func TestArticleUnmarshalAuthor(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want []Author
+	}{
+		{
+			name: "legacy author",
+			yaml: `author: "A. Kumar, B. Singh and C. Rao"`,
+			want: []Author{{Name: "A. Kumar"}, {Name: "B. Singh"}, {Name: "C. Rao"}},
+		},
+		{
+			name: "authors",
+			yaml: "authors:\n  - name: A. Kumar\n    affiliation: University of Delhi",
+			want: []Author{{Name: "A. Kumar", Affiliation: "University of Delhi"}},
+		},
+		{
+			name: "authors win over author",
+			yaml: "author: B. Singh\nauthors:\n  - name: A. Kumar",
+			want: []Author{{Name: "A. Kumar"}},
+		},
+		{
+			name: "no author",
+			yaml: "title: Editorial",
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var article Article
+			if err := yaml.UnmarshalStrict([]byte(tt.yaml), &article); err != nil {
+				t.Fatalf("UnmarshalStrict() error = %v", err)
+			}
+			if !reflect.DeepEqual(article.Authors, tt.want) {
+				t.Errorf("Authors = %+v, want %+v", article.Authors, tt.want)
+			}
+		})
+	}
+}
+
+func TestArticlesConfigRoundTrip(t *testing.T) {
+	articles := []Article{
+		{Title: "Editorial", Page: 1},
+		{Title: "Rivers of the Plains", Page: 3, Section: "Articles"},
+		{Title: "Lakes", Page: 9, Section: "Articles"},
+		{Title: "A Survey of Wells", Page: 15, Section: "Book Reviews"},
+		{Title: "Seas", Page: 21, Section: "Articles"},
+	}
+	config := NewArticlesConfig(articles)
+	var sections []string
+	for _, section := range config.Sections {
+		sections = append(sections, section.Title)
+	}
+	// Articles listed apart from the rest of their section start another entry of it
+	if want := []string{"Articles", "Book Reviews", "Articles"}; !reflect.DeepEqual(sections, want) {
+		t.Errorf("sections = %q, want %q", sections, want)
+	}
+
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var read ArticlesConfig
+	if err := yaml.UnmarshalStrict(data, &read); err != nil {
+		t.Fatalf("UnmarshalStrict() error = %v", err)
+	}
+	if got := read.AllArticles(); !reflect.DeepEqual(got, articles) {
+		t.Errorf("AllArticles() after a round trip = %+v, want %+v", got, articles)
+	}
+}
@@ -0,0 +1,48 @@
+package outline
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+// Entry is a bookmark of the document outline.
+type Entry struct {
+	Title string
+	// Page is the physical page the bookmark points to, or 0 when it has no page destination
+	Page int
+	// Level is the nesting depth of the bookmark, starting at 1 for top level bookmarks
+	Level int
+}
+
+// Read returns the bookmarks of the PDF in document order, or none when it has no outline.
+func Read(pdfPath string) ([]Entry, error) {
+	f, err := os.Open(pdfPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %v", pdfPath, err)
+	}
+	defer f.Close()
+
+	api.DisableConfigDir()
+	bookmarks, err := api.Bookmarks(f, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the outline of %s: %v", pdfPath, err)
+	}
+	var entries []Entry
+	flatten(bookmarks, 1, &entries)
+	return entries, nil
+}
+
+func flatten(bookmarks []pdfcpu.Bookmark, level int, entries *[]Entry) {
+	for _, b := range bookmarks {
+		*entries = append(*entries, Entry{
+			Title: strings.Join(strings.Fields(b.Title), " "),
+			Page:  b.PageFrom,
+			Level: level,
+		})
+		flatten(b.Kids, level+1, entries)
+	}
+}
@@ -0,0 +1,20 @@
+package services
+
+import (
+	"fmt"
+	"pdf-extractor/internal/cache"
+
+	"github.com/sirupsen/logrus"
+)
+
+func ClearCache(cacheDir string) error {
+	if cacheDir == "" {
+		return fmt.Errorf("no cache directory specified: use --cache-dir")
+	}
+	err := cache.New(cacheDir).Clear()
+	if err != nil {
+		return err
+	}
+	logrus.Infof("Cleared page text cache in %s", cacheDir)
+	return nil
+}
@@ -1,8 +1,16 @@
 package services
 
-import "pdf-extractor/internal/utils"
+import (
+	"os"
+	"pdf-extractor/internal/utils"
+)
 
-func Delete(file string, backupPath string, backupFlag bool) error {
+// Delete removes the file after backing it up. With planFormat set, what would be done is
+// printed in that format instead and nothing is written.
+func Delete(file string, backupPath string, backupFlag bool, planFormat string) error {
+	if err := validatePlanFormat(planFormat); err != nil {
+		return err
+	}
 	err := utils.CheckFileExists(file)
 	if err != nil {
 		return err
@@ -11,6 +19,13 @@ func Delete(file string, backupPath string, backupFlag bool) error {
 	if err != nil {
 		return err
 	}
+	if planFormat != "" {
+		var steps []planStep
+		if backupFlag {
+			steps = append(steps, backupStep(file, backupPath))
+		}
+		return printPlan(os.Stdout, append(steps, planStep{Action: "delete", Path: file}), planFormat)
+	}
 	if backupFlag {
 		// delete file "file" move it to the backup path
 		err = utils.CreateBackup(file, backupPath)
@@ -3,22 +3,51 @@ package services
 import (
 	"fmt"
 	"os"
-	"os/exec"
+	"pdf-extractor/internal/extractor"
 	"pdf-extractor/internal/utils"
+	"pdf-extractor/internal/writer"
 	"strings"
 
 	"github.com/sirupsen/logrus"
 )
 
-func DeletePages(file string, fromPage int, toPage int, atPage int, startsWith string, backupPath string, backupFlag bool) error {
-	// Implement the logic to delete pages from the PDF file
-	// This function should handle the deletion of pages based on the provided parameters
-	// and create a backup of the original file if backupPath is specified.
-
+// DeletePages removes the pages at atPage, from fromPage to toPage, or from the page starting
+// with startsWith to toPage, after backing up the file. With planFormat set, the pages are
+// printed in that format instead and nothing is written.
+func DeletePages(ext extractor.TextExtractor, pw writer.PageWriter, file string, fromPage int, toPage int, atPage int, startsWith string, backupPath string, backupFlag bool, planFormat string) error {
 	err := validate(fromPage, toPage, atPage, startsWith)
 	if err != nil {
 		return err
 	}
+	if err := validatePlanFormat(planFormat); err != nil {
+		return err
+	}
+
+	// Work out the pages to delete before anything is written
+	var from, to int
+	if atPage > 0 {
+		from, to, err = findPageAt(ext, file, atPage)
+	} else if startsWith != "" {
+		from, to, err = findPagesByContent(ext, file, startsWith, toPage)
+	} else {
+		from, to, err = findPagesRange(ext, file, fromPage, toPage)
+	}
+	if err != nil {
+		return err
+	}
+
+	if planFormat != "" {
+		var steps []planStep
+		if backupFlag {
+			steps = append(steps, backupStep(file, backupPath))
+		}
+		step := planStep{Action: "delete pages", From: from, To: to, Path: file}
+		if pages, _, err := loadPages(ext, file); err == nil {
+			step.Match = matchedSnippet(pages[from-1], startsWith)
+		}
+		return printPlan(os.Stdout, append(steps, step), planFormat)
+	}
+
 	err = utils.CreateDirectoryIfNotExists(backupPath)
 	if err != nil {
 		return err
@@ -29,16 +58,15 @@ func DeletePages(file string, fromPage int, toPage int, atPage int, startsWith s
 			return err
 		}
 	}
-	// Proceed with the delete logic
-	if atPage > 0 {
-		// Call deletePageAt function
-		deletePageAt(file, atPage)
-	} else if startsWith != "" {
-		// Call deletePagesByContent function
-		deletePagesByContent(file, startsWith, toPage)
-	} else if fromPage > 0 {
-		// Call deletePagesRange function
-		deletePagesRange(file, fromPage, toPage)
+	// Remove the pages from the original PDF
+	err = pw.RemovePages(file, from, to)
+	if err != nil {
+		return err
+	}
+	if from == to {
+		fmt.Printf("Successfully deleted page %d in '%s'.\n", from, file)
+	} else {
+		logrus.Infof("[INFO] Successfully deleted pages from %d to %d in '%s'.", from, to, file)
 	}
 	return nil
 }
@@ -69,57 +97,33 @@ func validate(fromPage int, toPage int, atPage int, startsWith string) error {
 	return nil
 }
 
-func deletePageAt(pdfPath string, page int) error {
+// findPageAt returns the page at, once it is checked to be in the PDF.
+func findPageAt(ext extractor.TextExtractor, pdfPath string, page int) (int, int, error) {
 	// Get the total number of pages in the PDF
-	totalPages, err := utils.GetPDFPageCount(pdfPath)
+	totalPages, err := ext.PageCount(pdfPath)
 	if err != nil {
-		return fmt.Errorf("failed to get page count: %v", err)
+		return 0, 0, fmt.Errorf("failed to get page count: %v", err)
 	}
 
 	// Validate if the page lies within the valid range
 	if page < 1 || page > totalPages {
-		return fmt.Errorf("invalid page number: %d (total pages: %d)", page, totalPages)
+		return 0, 0, fmt.Errorf("invalid page number: %d (total pages: %d)", page, totalPages)
 	}
-
-	// Construct the page ranges to keep
-	var pagesToKeep []string
-	if page > 1 {
-		pagesToKeep = append(pagesToKeep, fmt.Sprintf("1-%d", page-1))
-	}
-	if page < totalPages {
-		pagesToKeep = append(pagesToKeep, fmt.Sprintf("%d-%d", page+1, totalPages))
-	}
-
-	// Use pdftk to create a new PDF with the remaining pages
-	tempFile := "temp.pdf"
-	cmdArgs := append([]string{pdfPath, "cat"}, pagesToKeep...)
-	cmdArgs = append(cmdArgs, "output", tempFile)
-	cmd := exec.Command("pdftk", cmdArgs...)
-	err = cmd.Run()
-	if err != nil {
-		return fmt.Errorf("failed to delete page using pdftk: %v", err)
-	}
-
-	// Overwrite the original PDF with the new one
-	err = os.Rename(tempFile, pdfPath)
-	if err != nil {
-		return fmt.Errorf("failed to overwrite original PDF: %v", err)
-	}
-
-	fmt.Printf("Successfully deleted page %d in '%s'.\n", page, pdfPath)
-	return nil
+	return page, page, nil
 }
 
-func deletePagesByContent(pdfPath, startsWith string, to int) error {
-	// Get the total number of pages in the PDF
-	totalPages, err := utils.GetPDFPageCount(pdfPath)
+// findPagesByContent returns the pages from the first page starting with startsWith to the
+// page to, or to the last page.
+func findPagesByContent(ext extractor.TextExtractor, pdfPath, startsWith string, to int) (int, int, error) {
+	_, normalizedPages, err := loadPages(ext, pdfPath)
 	if err != nil {
-		return fmt.Errorf("failed to get page count: %v", err)
+		return 0, 0, err
 	}
+	totalPages := len(normalizedPages)
 
 	// Validate that 'to' is within the valid range
-	if to > totalPages || to == int(^uint(0)>>1) {
-		to = totalPages // Set 'to' to the last page if it's very large
+	if to <= 0 || to > totalPages {
+		to = totalPages // Set 'to' to the last page if it's unset or very large
 	}
 
 	// Debug log: Total pages and 'to' value
@@ -127,27 +131,14 @@ func deletePagesByContent(pdfPath, startsWith string, to int) error {
 
 	// Iterate through each page to find the page that starts with the specified string
 	startPage := -1
-	for page := 1; page <= totalPages; page++ {
-		// Extract the content of the current page
-		tempTxt := fmt.Sprintf("page_%d.txt", page)
-		err := utils.ExtractPDFPageWithPdftotext(pdfPath, tempTxt, page)
-		if err != nil {
-			logrus.Errorf("Failed to extract page %d: %v", page, err)
-			continue
-		}
-
-		// Read the content of the temporary file
-		content, err := os.ReadFile(tempTxt)
-		if err != nil {
-			logrus.Errorf("Failed to read content of page %d: %v\n", page, err)
-			os.Remove(tempTxt) // Clean up the temporary file
-			continue
-		}
-		os.Remove(tempTxt) // Clean up the temporary file
+	normalizedStartsWith := utils.NormalizeText(startsWith)
+	if normalizedStartsWith == "" {
+		return 0, 0, fmt.Errorf("--starts-with '%s' contains no letters or digits to match", startsWith)
+	}
+	for i, normalizedContent := range normalizedPages {
+		page := i + 1
 
-		// Normalize the content and compare with 'startsWith'
-		normalizedContent := utils.NormalizeText(string(content))
-		normalizedStartsWith := utils.NormalizeText(startsWith)
+		// Compare the normalized content with 'startsWith'
 
 		// Debug log: Print the starting words of the page
 		logrus.Debugf("[DEBUG] Page %d starts with: '%s'", page, normalizedContent[:min(len(normalizedContent), 50)])
@@ -162,83 +153,33 @@ func deletePagesByContent(pdfPath, startsWith string, to int) error {
 
 	// Validate that the 'starts-with' string was found
 	if startPage == -1 {
-		return fmt.Errorf("no page starts with the specified string: '%s'", startsWith)
+		return 0, 0, fmt.Errorf("no page starts with the specified string: '%s'", startsWith)
 	}
 
 	// Validate that 'to' is greater than or equal to the startPage
 	if to < startPage {
-		return fmt.Errorf("'to' (%d) must be greater than or equal to the page where 'starts-with' occurs (%d)", to, startPage)
-	}
-
-	// Construct the page ranges to keep
-	var pagesToKeep []string
-	if startPage > 1 {
-		pagesToKeep = append(pagesToKeep, fmt.Sprintf("1-%d", startPage-1))
-	}
-	if to < totalPages {
-		pagesToKeep = append(pagesToKeep, fmt.Sprintf("%d-%d", to+1, totalPages))
-	}
-
-	// Use pdftk to create a new PDF with the remaining pages
-	tempFile := "temp.pdf"
-	cmdArgs := append([]string{pdfPath, "cat"}, pagesToKeep...)
-	cmdArgs = append(cmdArgs, "output", tempFile)
-	cmd := exec.Command("pdftk", cmdArgs...)
-	err = cmd.Run()
-	if err != nil {
-		return fmt.Errorf("failed to delete pages using pdftk: %v", err)
-	}
-
-	// Overwrite the original PDF with the new one
-	err = os.Rename(tempFile, pdfPath)
-	if err != nil {
-		return fmt.Errorf("failed to overwrite original PDF: %v", err)
+		return 0, 0, fmt.Errorf("'to' (%d) must be greater than or equal to the page where 'starts-with' occurs (%d)", to, startPage)
 	}
-	logrus.Infof("[INFO] Successfully deleted pages starting from %d to %d in '%s'.", startPage, to, pdfPath)
-	return nil
+	return startPage, to, nil
 }
 
-func deletePagesRange(pdfPath string, from, to int) error {
+// findPagesRange returns the pages from to, or from to the last page, once they are checked
+// to be in the PDF.
+func findPagesRange(ext extractor.TextExtractor, pdfPath string, from, to int) (int, int, error) {
 	// Get the total number of pages in the PDF
-	totalPages, err := utils.GetPDFPageCount(pdfPath)
+	totalPages, err := ext.PageCount(pdfPath)
 	if err != nil {
-		return fmt.Errorf("failed to get page count: %v", err)
+		return 0, 0, fmt.Errorf("failed to get page count: %v", err)
 	}
 
-	// If 'to' is very large, set it to the total number of pages
-	if to > totalPages || to == int(^uint(0)>>1) {
+	// If 'to' is unset or very large, set it to the total number of pages
+	if to <= 0 || to > totalPages {
 		to = totalPages
 	}
 
 	// Validate the range
 	if from < 1 || from > totalPages || to < from {
-		return fmt.Errorf("invalid page range: from=%d, to=%d, totalPages=%d", from, to, totalPages)
-	}
-
-	// Construct the page ranges to keep
-	var pagesToKeep []string
-	if from > 1 {
-		pagesToKeep = append(pagesToKeep, fmt.Sprintf("1-%d", from-1))
-	}
-	if to < totalPages {
-		pagesToKeep = append(pagesToKeep, fmt.Sprintf("%d-%d", to+1, totalPages))
+		return 0, 0, fmt.Errorf("invalid page range: from=%d, to=%d, totalPages=%d", from, to, totalPages)
 	}
-
-	// Use pdftk to create a new PDF with the remaining pages
-	tempFile := "temp.pdf"
-	cmdArgs := append([]string{pdfPath, "cat"}, pagesToKeep...)
-	cmdArgs = append(cmdArgs, "output", tempFile)
-	cmd := exec.Command("pdftk", cmdArgs...)
-	err = cmd.Run()
-	if err != nil {
-		return fmt.Errorf("failed to delete pages using pdftk: %v", err)
-	}
-
-	// Overwrite the original PDF with the new one
-	err = os.Rename(tempFile, pdfPath)
-	if err != nil {
-		return fmt.Errorf("failed to overwrite original PDF: %v", err)
-	}
-	logrus.Infof("[INFO] Successfully deleted pages from %d to %d in '%s'.", from, to, pdfPath)
-	return nil
+	return from, to, nil
 }
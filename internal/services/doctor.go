@@ -0,0 +1,46 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"pdf-extractor/internal/tools"
+	"strings"
+	"text/tabwriter"
+)
+
+// Doctor reports every known external tool and fails when a required one is missing.
+func Doctor(required []string) error {
+	isRequired := map[string]bool{}
+	for _, name := range required {
+		isRequired[name] = true
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TOOL\tSTATUS\tVERSION\tENABLES")
+	var missing []string
+	for _, t := range tools.Known {
+		s := tools.Lookup(t)
+		status := "optional"
+		if isRequired[t.Name] {
+			status = "required"
+		}
+		version := s.Version
+		if !s.Found {
+			status += ", missing"
+			version = "-"
+			if isRequired[t.Name] {
+				missing = append(missing, t.Name)
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", t.Name, status, version, t.Capability)
+		if !s.Found {
+			fmt.Fprintf(w, "\t\t\tTo enable it, %s\n", t.Install)
+		}
+	}
+	w.Flush()
+
+	if len(missing) > 0 {
+		return fmt.Errorf("missing required tools: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
@@ -1,207 +1,272 @@
 package services
 
 import (
-	"bufio"
 	"fmt"
 	"os"
 	"path/filepath"
+	"pdf-extractor/internal/extractor"
 	"pdf-extractor/internal/models"
+	"pdf-extractor/internal/outline"
+	"pdf-extractor/internal/toc"
 	"pdf-extractor/internal/utils"
-	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v2"
 )
 
-func ExtractIndex(file string, outputPath string) error {
-	// Ensure the output directory exists
-	err := utils.CreateDirectoryIfNotExists(outputPath)
+const (
+	SourceAuto    = "auto"
+	SourceOutline = "outline"
+	SourceText    = "text"
+)
+
+// ExtractIndex writes the articles of the PDF to config.yaml, reading them from the outline or
+// by parsing the table of contents with the parser for its layout. The contents are read from
+// contentsPages ("3-5") or start at contentsPage when given, and are otherwise looked up under
+// the contents keywords. Lines repeating on more than headerThreshold of the odd or even pages
+// are dropped from the contents as running headers. With interactive set, the entries are
+// reviewed on the terminal before they are saved.
+func ExtractIndex(ext extractor.TextExtractor, file string, outputPath string, contentsPages string, contentsPage int, contentsKeywords []string, source string, layout string, headerThreshold float64, interactive bool) error {
+	if source != SourceAuto && source != SourceOutline && source != SourceText {
+		return fmt.Errorf("unknown source '%s': expected '%s', '%s' or '%s'", source, SourceAuto, SourceOutline, SourceText)
+	}
+	if headerThreshold <= 0 || headerThreshold > 1 {
+		return fmt.Errorf("invalid --header-threshold %v: must be greater than 0 and at most 1", headerThreshold)
+	}
+	parser, err := toc.New(layout, contentsKeywords)
 	if err != nil {
 		return err
 	}
+	fromPage, toPage := contentsPage, 0
+	if contentsPage < 0 {
+		return fmt.Errorf("invalid --contents-page %d: pages start at 1", contentsPage)
+	}
+	if contentsPages != "" {
+		if contentsPage > 0 {
+			return fmt.Errorf("--contents-pages and --contents-page cannot be used together")
+		}
+		fromPage, toPage, err = utils.ParsePageRange(contentsPages)
+		if err != nil {
+			return fmt.Errorf("invalid --contents-pages: %v", err)
+		}
+	}
 
-	// Extract the page with the title "Contents" directly into memory
-	contentsPage, err := extractContentsPageInMemory(file)
+	// Ensure the output directory exists
+	err = utils.CreateDirectoryIfNotExists(outputPath)
 	if err != nil {
-		return fmt.Errorf("error extracting content: %v", err)
+		return err
 	}
 
-	// Parse the extracted content to extract titles and authors
-	articles, err := parseTitlesAndAuthorsFromContent(contentsPage)
-	if err != nil {
-		return fmt.Errorf("error parsing titles and authors: %v", err)
+	// Pages or keywords given for the contents ask for the text to be parsed
+	var entries []toc.Entry
+	report := indexReport{File: file, Source: SourceOutline}
+	if source == SourceOutline || (source == SourceAuto && fromPage == 0 && len(contentsKeywords) == 0) {
+		entries, err = readArticlesFromOutline(file)
+		switch {
+		case err != nil && source == SourceOutline:
+			return err
+		case err != nil:
+			logrus.Warnf("%v, parsing the contents instead", err)
+		case entries == nil && source == SourceOutline:
+			return fmt.Errorf("the PDF has no outline")
+		case entries == nil:
+			// Most PDFs have no outline, so auto falls back to the contents quietly
+			logrus.Infof("The PDF has no outline, parsing the contents instead")
+		}
+	}
+
+	if entries == nil {
+		report.Source, report.Layout = SourceText, parser.Name()
+		// Extract the pages of the table of contents directly into memory
+		contents, err := extractContentsPagesInMemory(ext, file, fromPage, toPage, parser, headerThreshold)
+		if err != nil {
+			return fmt.Errorf("error extracting content: %v", err)
+		}
+
+		// Parse the extracted content to extract titles and authors
+		entries, err = parser.Parse(contents)
+		if err != nil {
+			return fmt.Errorf("error parsing titles and authors: %v", err)
+		}
+		fmt.Printf("Read %d articles from the contents using the '%s' layout\n", len(entries), parser.Name())
+		for _, section := range models.NewArticlesConfig(articlesOf(entries)).Sections {
+			fmt.Printf("Section '%s' lists %d articles\n", section.Title, len(section.Articles))
+		}
+	}
+	if interactive {
+		entries, err = reviewIndex(entries, parser, report.Source == SourceOutline, os.Stdin, os.Stdout)
+		if err != nil {
+			return fmt.Errorf("%v, %s was not written", err, filepath.Join(outputPath, "config.yaml"))
+		}
 	}
+	articles := articlesOf(entries)
 
 	// Debug: Print the articles array
 	logrus.Debugf("Extracted Articles: %+v\n", articles)
 
 	// Save articles and authors to config.yaml
 	yamlFilePath := filepath.Join(outputPath, "config.yaml")
-	err = saveArticlesAndAuthorsToYAML(articles, yamlFilePath)
+	err = saveArticlesAndAuthorsToYAML(articles, parser.ContentsKeywords(), yamlFilePath)
 	if err != nil {
 		return fmt.Errorf("error saving articles and authors to yaml: %v", err)
 	}
 
 	logrus.Infof("Articles and authors saved successfully to %s", yamlFilePath)
+
+	// Say how sure we are of every entry, so that only the doubtful ones need checking
+	report.Entries = reviewEntries(entries, report.Source == SourceOutline)
+	reportPath := filepath.Join(outputPath, "index-report.json")
+	if err := saveIndexReport(report, reportPath); err != nil {
+		return err
+	}
+	printLowConfidence(report.Entries, reportPath)
 	return nil
 }
-func extractContentsPageInMemory(pdfPath string) (string, error) {
-	// Get the total number of pages in the PDF
-	totalPages, err := utils.GetPDFPageCount(pdfPath)
+
+// articlesOf returns the articles of the entries.
+func articlesOf(entries []toc.Entry) []models.Article {
+	articles := make([]models.Article, len(entries))
+	for i, entry := range entries {
+		articles[i] = entry.Article
+	}
+	return articles
+}
+
+// readArticlesFromOutline returns an article for every bookmark of the outline, or none
+// when the PDF has no outline.
+func readArticlesFromOutline(pdfPath string) ([]toc.Entry, error) {
+	entries, err := outline.Read(pdfPath)
 	if err != nil {
-		return "", fmt.Errorf("failed to get page count: %v", err)
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, nil
 	}
 
-	// Iterate through each page to find the "Contents" page
-	for page := 1; page <= totalPages; page++ {
-		tempFile := filepath.Join(os.TempDir(), fmt.Sprintf("page_%d.txt", page))
+	articles := make([]toc.Entry, 0, len(entries))
+	for _, e := range entries {
+		articles = append(articles, toc.Entry{
+			Article: models.Article{
+				Title:        e.Title,
+				PhysicalPage: e.Page,
+				Level:        e.Level,
+			},
+			TitleLines: 1,
+		})
+	}
+	fmt.Printf("Read %d articles from the outline\n", len(articles))
+	return articles, nil
+}
 
-		// Extract the current page using pdftotext
-		err := utils.ExtractPDFPageWithPdftotext(pdfPath, tempFile, page)
-		if err != nil {
-			return "", fmt.Errorf("failed to extract page %d: %v", page, err)
+// extractContentsPagesInMemory returns the text of the table of contents, which either spans
+// the pages from..to or starts at page from, or at the page headed by one of the contents
+// keywords, and continues over the following pages for as long as they carry on numbering its
+// entries. Contents set in several columns are read one column after the other.
+func extractContentsPagesInMemory(ext extractor.TextExtractor, pdfPath string, from, to int, parser toc.TOCParser, headerThreshold float64) (string, error) {
+	pages, layout, err := loadPagesWithLayout(ext, pdfPath)
+	if err != nil {
+		return "", err
+	}
+	if columns := parser.Columns(); columns > 1 {
+		if layout != nil {
+			for i := range pages {
+				pages[i] = readColumns(layout[i], columns)
+			}
+		} else {
+			logrus.Warnf("The page layout is not available, so the %d columns of the contents are read line by line", columns)
 		}
+	}
+	// Running headers and footers would otherwise be read as titles or authors
+	cleaned := removeRunningHeaders(pages, headerThreshold)
 
-		// Read the extracted content from the temporary file
-		content, err := os.ReadFile(tempFile)
-		if err != nil {
-			return "", fmt.Errorf("failed to read temporary file for page %d: %v", page, err)
+	if from > 0 && to > 0 {
+		if to > len(pages) {
+			return "", fmt.Errorf("invalid --contents-pages %d-%d: the PDF has %d pages", from, to, len(pages))
 		}
-		// Check if the page contains the word "Contents"
-		// also normalize before compare case
-
-		if strings.Contains(utils.NormalizeText(string(content)), "contents") {
-			// Remove the temporary file
-			os.Remove(tempFile)
-
-			fmt.Printf("Found 'Contents' on page %d\n", page)
-			return string(content), nil
+		fmt.Printf("Reading contents from pages %d to %d\n", from, to)
+		contents := cleaned[from-1]
+		for page := from + 1; page <= to; page++ {
+			next, _ := parser.Continuation(contents, cleaned[page-1])
+			contents += "\n" + next
 		}
-
-		// Remove the temporary file
-		os.Remove(tempFile)
+		return contents, nil
 	}
 
-	return "", fmt.Errorf("'Contents' page not found in the PDF")
-}
-func parseTitlesAndAuthorsFromContent(content string) ([]models.Article, error) {
-	// Regular expression to match article numbers (e.g., "1.")
-	numberRegex := regexp.MustCompile(`^\d+\.\s*`)
-	// Regular expression to match lines with only a number or a number range (e.g., "1", "10", "1-10")
-	numberOrRangeRegex := regexp.MustCompile(`^\d+(-\d+)?$`)
-
-	var articles []models.Article
-	scanner := bufio.NewScanner(strings.NewReader(content))
-	var titleLines []string
-	var prevText string     // To store the last appended text
-	var foundIndex bool     // Flag to indicate if an article index has been found
-	var expectingTitle bool // Flag to indicate if the next line is the title
-
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		fmt.Printf("Scanning line: '%s'\n", line) // Debugging line
-
-		// Skip empty lines
-		if line == "" {
-			continue
+	start := from
+	if start > len(pages) {
+		return "", fmt.Errorf("invalid --contents-page %d: the PDF has %d pages", start, len(pages))
+	}
+	if start > 0 {
+		fmt.Printf("Reading contents from page %d\n", start)
+	} else {
+		keywords := parser.ContentsKeywords()
+		start = findContentsPage(layout, pages, keywords)
+		if start == 0 {
+			return "", fmt.Errorf("no page is headed %s: use --contents-keyword or --contents-page", quoteAll(keywords))
 		}
+		fmt.Printf("Found the contents on page %d\n", start)
+	}
 
-		// Skip lines that contain only a number or a number range
-		if numberOrRangeRegex.MatchString(line) {
-			fmt.Printf("Skipping line with only a number or range: '%s'\n", line) // Debugging line
-			continue
+	contents := cleaned[start-1]
+	for page := start + 1; page <= len(pages); page++ {
+		next, ok := parser.Continuation(contents, cleaned[page-1])
+		if !ok {
+			break
 		}
+		fmt.Printf("Contents continue on page %d\n", page)
+		contents += "\n" + next
+	}
+	return contents, nil
+}
 
-		// Match article numbers
-		if numberRegex.MatchString(line) {
-			fmt.Printf("Detected article number: '%s'\n", line) // Debugging line
-			foundIndex = true
-
-			// If a new article number is found, save the previous article (if any)
-			if len(titleLines) > 0 {
-				// Remove the last line from titleLines (it is the author)
-				if len(titleLines) > 1 {
-					prevText = titleLines[len(titleLines)-1]
-					titleLines = titleLines[:len(titleLines)-1]
-				} else if len(titleLines) == 1 {
-					// If there's only one line, treat it as the title and leave prevText empty
-					prevText = ""
-				}
-
-				// Combine all title lines into a single title
-				for i := range titleLines {
-					titleLines[i] = utils.TrimTrailingNumber(titleLines[i])
-				}
-				title := strings.Join(titleLines, " ")
-				title = strings.TrimSpace(title)
-				title = strings.TrimSuffix(title, ".")
-				// trim any trailing number or digits
-				title = utils.TrimTrailingNumber(title)
-				articles = append(articles, models.Article{
-					Title:  title,
-					Author: prevText,
-				})
-
-				fmt.Printf("Added article: Title='%s', Author='%s'\n", title, prevText) // Debugging line
-				titleLines = nil                                                        // Reset for the next article
-			}
+// findContentsPage returns the page the table of contents starts on, or 0.
+func findContentsPage(layout []extractor.Page, pages []string, keywords []string) int {
+	isHeading := func(line string) bool {
+		return toc.IsContentsHeading(line, keywords)
+	}
+	// Prefer the position of the lines on the page when it is known
+	if layout != nil {
+		return findHeadingPage(layout, isHeading)
+	}
 
-			// Extract the title from the same line if it contains both the index and the title
-			title := numberRegex.ReplaceAllString(line, "")
-			if title != "" {
-				titleLines = append(titleLines, title)
-				expectingTitle = false // Reset the flag since the title is already extracted
-			} else {
-				expectingTitle = true // The next line(s) should be treated as the title
+	// Otherwise the heading must be among the first lines of the page
+	for i, content := range pages {
+		n := 0
+		for _, line := range strings.Split(content, "\n") {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			if isHeading(line) {
+				return i + 1
+			}
+			if n++; n == headingTopLines {
+				break
 			}
-		} else if expectingTitle {
-			// Append the current line to the title
-			fmt.Printf("Appending to title: '%s'\n", line) // Debugging line
-			titleLines = append(titleLines, line)
-			expectingTitle = false // Reset the flag after appending the first title line
-		} else if foundIndex {
-			// Append the current line to the title if an index has been found
-			fmt.Printf("Appending to title: '%s'\n", line) // Debugging line
-			titleLines = append(titleLines, line)
 		}
 	}
+	return 0
+}
 
-	// Handle the last article
-	if len(titleLines) > 0 {
-		// Remove the last line from titleLines (it is the author)
-		if len(titleLines) > 1 {
-			prevText = titleLines[len(titleLines)-1]
-			titleLines = titleLines[:len(titleLines)-1]
-		} else if len(titleLines) == 1 {
-			// If there's only one line, treat it as the title and leave prevText empty
-			prevText = ""
-		}
-		for i := range titleLines {
-			titleLines[i] = utils.TrimTrailingNumber(titleLines[i])
-		}
-		// Combine all title lines into a single title
-		title := strings.Join(titleLines, " ")
-		title = strings.TrimSpace(title)
-		title = strings.TrimSuffix(title, ".")
-		title = utils.TrimTrailingNumber(title)
-		articles = append(articles, models.Article{
-			Title:  title,
-			Author: prevText,
-		})
-		fmt.Printf("Added last article: Title='%s', Author='%s'\n", title, prevText) // Debugging line
+// quoteAll quotes the words and joins them with "or".
+func quoteAll(words []string) string {
+	quoted := make([]string, len(words))
+	for i, w := range words {
+		quoted[i] = fmt.Sprintf("'%s'", w)
 	}
-
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading content: %v", err)
+	if len(quoted) < 2 {
+		return strings.Join(quoted, "")
 	}
-
-	return articles, nil
+	return strings.Join(quoted[:len(quoted)-1], ", ") + " or " + quoted[len(quoted)-1]
 }
-func saveArticlesAndAuthorsToYAML(articles []models.Article, filePath string) error {
+
+func saveArticlesAndAuthorsToYAML(articles []models.Article, contentsKeywords []string, filePath string) error {
 	// Create the YAML structure
-	config := models.ArticlesConfig{
-		Articles: articles,
+	config := models.NewArticlesConfig(articles)
+	// extract skips the pages headed by the keywords, so keywords other than the default are kept
+	if !slices.Equal(contentsKeywords, toc.DefaultContentsKeywords) {
+		config.ContentsKeywords = contentsKeywords
 	}
 
 	// Create or overwrite the YAML file
@@ -1,36 +1,63 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"pdf-extractor/internal/extractor"
 	"pdf-extractor/internal/models"
+	"pdf-extractor/internal/toc"
 	"pdf-extractor/internal/utils"
+	"pdf-extractor/internal/writer"
+	"slices"
 	"strings"
 
 	"github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v2"
 )
 
-func ExtractPDFFromRange(extractFile string, outputPath string, fromPage int, toPage int, articleTitle string) error {
-	// check if the extractFile exists
-	err := utils.CheckFileExists(extractFile)
+// ExtractPDFFromRange writes the pages fromPage..toPage to a PDF named by the name template
+// for the article. With planFormat set, the plan is printed in that format instead and nothing
+// is written.
+func ExtractPDFFromRange(ext extractor.TextExtractor, pw writer.PageWriter, extractFile string, outputPath string, fromPage int, toPage int, articleTitle string, nameTemplate string, slug string, planFormat string) error {
+	if err := validatePlanFormat(planFormat); err != nil {
+		return err
+	}
+	names, err := newNameTemplate(nameTemplate, slug)
 	if err != nil {
 		return err
 	}
-	// create outputPath if it does not exist
-	err = utils.CreateDirectoryIfNotExists(outputPath)
+	// check if the extractFile exists
+	err = utils.CheckFileExists(extractFile)
 	if err != nil {
 		return err
 	}
-	err = validateRange(extractFile, fromPage, toPage)
+	err = validateRange(ext, extractFile, fromPage, toPage)
 	if err != nil {
 		return err
 	}
 	// Generate pdf file
-	outputFile := filepath.Join(outputPath, fmt.Sprintf("%s.pdf", utils.SanitizeFileName(articleTitle)))
-	err = extractPDFPages(extractFile, outputFile, fromPage, toPage)
+	fileName, err := names.fileName(nameValues{
+		index:   1,
+		article: models.Article{Title: articleTitle},
+		start:   fromPage,
+		end:     toPage,
+		source:  extractFile,
+	})
+	if err != nil {
+		return err
+	}
+	outputFile := filepath.Join(outputPath, fileName)
+	if planFormat != "" {
+		return printPlan(os.Stdout, []planStep{{Action: "extract", Title: articleTitle, From: fromPage, To: toPage, Path: outputFile}}, planFormat)
+	}
+	// create outputPath if it does not exist
+	err = utils.CreateDirectoryIfNotExists(outputPath)
+	if err != nil {
+		return err
+	}
+	err = pw.ExtractPages(extractFile, outputFile, fromPage, toPage)
 	if err != nil {
 		return fmt.Errorf("failed to extract pages from %s: %v", extractFile, err)
 	}
@@ -38,12 +65,31 @@ func ExtractPDFFromRange(extractFile string, outputPath string, fromPage int, to
 	return nil
 }
 
-func ExtractPDF(extractFile string, outputPath string, configPath string, endsWith string) error {
-
-	err := utils.RecreateDirectory(outputPath)
+// ExtractPDF writes a PDF for every article of the config.yaml in configPath to outputPath,
+// which is emptied first, naming the files by the name template. Pages headed by the contents
+// keywords, or else those kept in config.yaml, are not searched for titles. With planFormat
+// set, the page ranges are printed in that format instead and nothing is written.
+func ExtractPDF(ext extractor.TextExtractor, pw writer.PageWriter, extractFile string, outputPath string, configPath string, endsWith string, contentsKeywords []string, headerThreshold float64, matchThreshold float64, jobs int, nameTemplate string, slug string, planFormat string) error {
+	if headerThreshold <= 0 || headerThreshold > 1 {
+		return fmt.Errorf("invalid --header-threshold %v: must be greater than 0 and at most 1", headerThreshold)
+	}
+	if matchThreshold <= 0 || matchThreshold > 1 {
+		return fmt.Errorf("invalid --match-threshold %v: must be greater than 0 and at most 1", matchThreshold)
+	}
+	if err := validatePlanFormat(planFormat); err != nil {
+		return err
+	}
+	names, err := newNameTemplate(nameTemplate, slug)
 	if err != nil {
 		return err
 	}
+
+	if planFormat == "" {
+		err := utils.RecreateDirectory(outputPath)
+		if err != nil {
+			return err
+		}
+	}
 	// validate the extractFile
 	err = utils.CheckFileExists(extractFile)
 	if err != nil {
@@ -56,25 +102,34 @@ func ExtractPDF(extractFile string, outputPath string, configPath string, endsWi
 		return err
 	}
 	// Read articles from the config.yaml file
-	articles, err := readArticlesFromConfig(configFilePath)
+	articles, configKeywords, err := readArticlesFromConfig(configFilePath)
 	if err != nil {
 		return fmt.Errorf("error reading articles: %v", err)
 	}
+	if len(contentsKeywords) == 0 {
+		contentsKeywords = configKeywords
+	}
+	if len(contentsKeywords) == 0 {
+		contentsKeywords = toc.DefaultContentsKeywords
+	}
 
 	// Extract pages for each article
-	err = extractPagesForArticles(extractFile, articles, outputPath, endsWith)
+	err = extractPagesForArticles(ext, pw, extractFile, articles, outputPath, endsWith, contentsKeywords, headerThreshold, matchThreshold, jobs, names, planFormat)
 	if err != nil {
 		return fmt.Errorf("error extracting pages: %v", err)
 	}
+	if planFormat != "" {
+		return nil
+	}
 
 	logrus.Infof("Pages successfully extracted for all articles in %s", outputPath)
 
 	return nil
 }
 
-func validateRange(pdfPath string, fromPage int, toPage int) error {
+func validateRange(ext extractor.TextExtractor, pdfPath string, fromPage int, toPage int) error {
 	// Get the total number of pages in the PDF
-	totalPages, err := utils.GetPDFPageCount(pdfPath)
+	totalPages, err := ext.PageCount(pdfPath)
 	if err != nil {
 		return fmt.Errorf("failed to get page count: %v", err)
 	}
@@ -84,230 +139,303 @@ func validateRange(pdfPath string, fromPage int, toPage int) error {
 	return nil
 }
 
-func readArticlesFromConfig(filePath string) ([]string, error) {
+func readArticlesFromConfig(filePath string) ([]models.Article, []string, error) {
 	// Read the YAML file
 	data, err := os.ReadFile(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read config.yaml: %v", err)
+		return nil, nil, fmt.Errorf("failed to read config.yaml: %v", err)
 	}
 
 	// Parse the YAML file
 	var config models.ArticlesConfig
 	err = yaml.Unmarshal(data, &config)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse config.yaml: %v", err)
+		return nil, nil, fmt.Errorf("failed to parse config.yaml: %v", err)
+	}
+	// Keys that are not read, such as a misspelled endWith, are reported but do not fail the command
+	if err := yaml.UnmarshalStrict(data, &models.ArticlesConfig{}); err != nil {
+		logrus.Warnf("Ignoring unknown keys in %s: %v", filePath, err)
 	}
 
-	// Extract article titles
-	var titles []string
-	for _, article := range config.Articles {
-		titles = append(titles, article.Title)
+	// Keep the articles that have a title to match or a start page set
+	var articles []models.Article
+	for _, article := range config.AllArticles() {
+		if err := validateOverrides(article); err != nil {
+			return nil, nil, err
+		}
+		if utils.NormalizeText(article.Title) == "" && article.FixedStart() == 0 {
+			logrus.Warnf("Skipping article '%s': its title contains no letters or digits to match", article.Title)
+			continue
+		}
+		articles = append(articles, article)
 	}
 
-	return titles, nil
+	return articles, config.ContentsKeywords, nil
 }
 
-func extractPagesForArticles(pdfPath string, articles []string, outputPath string, endsWith string) error {
-	outputFile := ""
-	const patternThreshold = 0.6 // 80% threshold
-	// Get the total number of pages in the PDF
-	totalPages, err := utils.GetPDFPageCount(pdfPath)
-	if err != nil {
-		return fmt.Errorf("failed to get page count: %v", err)
+// validateOverrides checks the fields of an article that are set by hand.
+func validateOverrides(article models.Article) error {
+	if article.Start < 0 || article.End < 0 {
+		return fmt.Errorf("article '%s': start and end must not be negative", article.Title)
 	}
+	if article.Start > 0 && article.End > 0 && article.End < article.Start {
+		return fmt.Errorf("article '%s': end %d comes before start %d", article.Title, article.End, article.Start)
+	}
+	if article.Filename != "" && filepath.Base(article.Filename) != article.Filename {
+		return fmt.Errorf("article '%s': filename '%s' must be a file name without a directory", article.Title, article.Filename)
+	}
+	return nil
+}
 
-	// Map to store the starting page of each article
-	articlePages := make(map[string]int)
-
-	// Array to store normalized content of all pages
-	pageContents := make([]string, totalPages)
-
-	// Iterate through each page to extract and store normalized content
-	for page := 1; page <= totalPages; page++ {
-		tempFile := filepath.Join(os.TempDir(), fmt.Sprintf("page_%d.txt", page))
-
-		// Extract the current page using pdftotext
-		err := utils.ExtractPDFPageWithPdftotext(pdfPath, tempFile, page)
-		if err != nil {
-			return fmt.Errorf("failed to extract page %d: %v", page, err)
-		}
-
-		// Read the extracted content from the temporary file
-		content, err := os.ReadFile(tempFile)
-		if err != nil {
-			return fmt.Errorf("failed to read temporary file for page %d: %v", page, err)
-		}
-
-		// Normalize the extracted content by removing line breaks
-		normalizedContent := utils.NormalizeText(string(content))
-		pageContents[page-1] = normalizedContent // Store normalized content in the array
+// articleRange is the page range written to the output file of an article.
+type articleRange struct {
+	title      string
+	startPage  int
+	endPage    int
+	outputFile string
+	// match is the text on the start page the article was matched to
+	match string
+	// skip is set for articles the config leaves out
+	skip bool
+}
 
-		// Remove the temporary file
-		os.Remove(tempFile)
-	}
-	longestPrefix, err := findLongestPrefix(pageContents, patternThreshold)
+func extractPagesForArticles(ext extractor.TextExtractor, pw writer.PageWriter, pdfPath string, articles []models.Article, outputPath string, endsWith string, contentsKeywords []string, headerThreshold float64, matchThreshold float64, jobs int, names *nameTemplate, planFormat string) error {
+	pages, layout, err := loadPagesWithLayout(ext, pdfPath)
 	if err != nil {
-		return fmt.Errorf("error finding prefix: %v", err)
-	} else {
-		logrus.Debugf("Longest prefix found: '%s'", longestPrefix)
+		return err
+	}
+	totalPages := len(pages)
+
+	// Normalized content of all pages without their running headers and footers
+	cleaned := removeRunningHeaders(pages, headerThreshold)
+	tops := pageTops(cleaned)
+	pageContents := make([]string, len(cleaned))
+	for i, content := range cleaned {
+		pageContents[i] = utils.NormalizeText(content)
 	}
-
-	pageContents = removePrefix(pageContents, longestPrefix)
 	// find starting pages for articles
-	for _, article := range articles {
-		for page, normalizedContent := range pageContents {
-
-			if matchArticleTitleByLength(normalizedContent, article) {
-				logrus.Debugf("Found article '%s' on page %d", article, page+1)
-				articlePages[article] = page + 1 // Pages are 1-indexed
-				break
+	articlePages := findStartPages(layout, pageContents, tops, articles, contentsKeywords, matchThreshold)
+
+	// Once the offset between printed and physical pages is known, the printed page
+	// numbers from the contents decide and the titles found only verify them
+	if offset, ok := detectPageOffset(articles, articlePages); ok {
+		for i, article := range articles {
+			if article.Page == 0 || article.FixedStart() > 0 {
+				continue
+			}
+			page := article.Page + offset
+			if page < 1 || page > totalPages {
+				logrus.Warnf("Printed page %d of article '%s' is outside the PDF (physical page %d of %d)", article.Page, article.Title, page, totalPages)
+				continue
+			}
+			if found := articlePages[i]; found > 0 && found != page {
+				logrus.Warnf("Article '%s' starts on page %d by its printed page %d, but its title was found on page %d", article.Title, page, article.Page, found)
 			}
+			articlePages[i] = page
+		}
+	}
+	for i, article := range articles {
+		if page := article.FixedStart(); page > 0 {
+			if page > totalPages {
+				logrus.Warnf("Article '%s' points to page %d, but the PDF has %d pages", article.Title, page, totalPages)
+				continue
+			}
+			articlePages[i] = page
 		}
 	}
 
-	// Extract pages for each article
+	// Determine the page range of each article
+	var ranges []articleRange
 	for i, article := range articles {
-		startPage, ok := articlePages[article]
-		if !ok {
-			logrus.Warnf("Article '%s' not found in the PDF.", article)
+		startPage := articlePages[i]
+		if startPage == 0 {
+			logrus.Warnf("Article '%s' not found in the PDF.", article.Title)
 			continue
 		}
-		var endPage int
-
-		// Determine the end page
-
-		if i+1 < len(articles) {
-			nextArticle := articles[i+1]
-			if nextStartPage, ok := articlePages[nextArticle]; ok {
-				endPage = nextStartPage - 1
-			} else {
-				endPage = totalPages
-			}
-		} else {
-			// logrus.Warnln("This is the last article")
-			// Handle the last article
-			endPage = totalPages
-			if endsWith != "" {
-				pageFound, err := findPageEndingWith(pdfPath, endsWith, startPage, totalPages, pageContents)
-				if err != nil {
-					return fmt.Errorf("error finding page for --ends-with: %v", err)
-				} else if pageFound > 0 {
-					endPage = pageFound - 1
-				}
-			}
+		endPage, err := articleEndPage(articles, articlePages, i, endsWith, pageContents)
+		if err != nil {
+			return err
 		}
 		// logrus.Warnf("Endpage for article '%s' is %d", article, endPage)
 		// Validate page range
 		if startPage > endPage || startPage < 1 || endPage > totalPages {
-			return fmt.Errorf("invalid page range for article '%s' (start: %d, end: %d)", article, startPage, endPage)
+			return fmt.Errorf("invalid page range for article '%s' (start: %d, end: %d)", article.Title, startPage, endPage)
 		}
 
-		// Generate the output file path using chapterOutputPath
-		outputFile = filepath.Join(outputPath, fmt.Sprintf("%s.pdf", utils.SanitizeFileName(article)))
-
-		// Extract the pages for the current article
-		err := extractPDFPages(pdfPath, outputFile, startPage, endPage)
+		// Articles of a section are written to a directory named after it
+		articleOutputPath := outputPath
+		if article.Section != "" {
+			articleOutputPath = filepath.Join(outputPath, slugify(article.Section, names.slug))
+		}
+		fileName, err := names.fileName(nameValues{index: i + 1, article: article, start: startPage, end: endPage, source: pdfPath})
 		if err != nil {
-			return fmt.Errorf("failed to extract pages for article '%s': %v", article, err)
+			return err
 		}
-		logrus.Infof("Extracted pages %d to %d for article '%s' into '%s'", startPage, endPage, article, outputFile)
+		ranges = append(ranges, articleRange{
+			title:      article.Title,
+			startPage:  startPage,
+			endPage:    endPage,
+			outputFile: filepath.Join(articleOutputPath, fileName),
+			match:      matchedSnippet(cleaned[startPage-1], article.Title),
+			skip:       article.Skip,
+		})
 	}
 
-	return nil
-}
-
-func findLongestPrefix(stringsList []string, threshold float64) (string, error) {
-	processedStrings := make([]string, len(stringsList))
-	for i, str := range stringsList {
-		processedStrings[i] = utils.RemoveDigits(str)
+	if len(ranges) == 0 && len(articles) > 0 {
+		return fmt.Errorf("none of the %d articles in the config were found in the PDF", len(articles))
 	}
-
-	totalStrings := len(processedStrings)
-	if totalStrings == 0 {
-		return "", fmt.Errorf("no strings provided")
+	uniqueOutputFiles(ranges)
+	if planFormat != "" {
+		steps := make([]planStep, len(ranges))
+		for i, r := range ranges {
+			steps[i] = planStep{Action: "extract", Title: r.title, From: r.startPage, To: r.endPage, Match: r.match, Path: r.outputFile}
+			if r.skip {
+				steps[i].Action, steps[i].Path = "skip", ""
+			}
+		}
+		return printPlan(os.Stdout, steps, planFormat)
+	}
+	ranges = slices.DeleteFunc(ranges, func(r articleRange) bool {
+		if r.skip {
+			logrus.Infof("Skipping article '%s' on pages %d to %d, as the config asks", r.title, r.startPage, r.endPage)
+		}
+		return r.skip
+	})
+	for _, r := range ranges {
+		if err := utils.CreateDirectoryIfNotExists(filepath.Dir(r.outputFile)); err != nil {
+			return err
+		}
 	}
 
-	// Helper function to find prefix in a given order
-	findPrefix := func(strings []string) string {
-		firstString := strings[0]
-		longestPrefix := ""
-
-		for i := 1; i <= len(firstString); i++ {
-			prefix := firstString[:i]
-			count := 0
-
-			for _, str := range strings {
-				if stringStartsWith(str, prefix) {
-					count++
-				}
-			}
+	// Extract the pages for each article, several articles at a time
+	return utils.RunParallel(jobs, len(ranges), func(_ context.Context, i int) error {
+		r := ranges[i]
+		err := pw.ExtractPages(pdfPath, r.outputFile, r.startPage, r.endPage)
+		if err != nil {
+			return fmt.Errorf("failed to extract pages for article '%s': %v", r.title, err)
+		}
+		logrus.Infof("Extracted pages %d to %d for article '%s' into '%s'", r.startPage, r.endPage, r.title, r.outputFile)
+		return nil
+	})
+}
 
-			occurrenceRate := float64(count) / float64(totalStrings)
-			if occurrenceRate >= threshold && len(prefix) > len(longestPrefix) {
-				longestPrefix = prefix
-			}
+// uniqueOutputFiles gives every article that is written a file of its own. When several would
+// be written to the same file, as two articles titled "Editorial" would, a number is added to
+// the name of all but the first, as in "Editorial-2.pdf".
+func uniqueOutputFiles(ranges []articleRange) {
+	taken := map[string]bool{}
+	for i, r := range ranges {
+		if r.skip {
+			continue
 		}
-		return longestPrefix
+		ext := filepath.Ext(r.outputFile)
+		path := r.outputFile
+		// File names differing only in case are the same file on some file systems
+		for n := 2; taken[strings.ToLower(path)]; n++ {
+			path = fmt.Sprintf("%s-%d%s", strings.TrimSuffix(r.outputFile, ext), n, ext)
+		}
+		if path != r.outputFile {
+			logrus.Warnf("Article '%s' would be written to %s like an article before it, so it is written to %s", r.title, r.outputFile, path)
+			ranges[i].outputFile = path
+		}
+		taken[strings.ToLower(path)] = true
 	}
+}
 
-	// Find prefix from the start
-	startPrefix := findPrefix(processedStrings)
+// articleEndPage returns the last page of article i: the end set in the config, the page
+// before the one starting with its endsWith text, or the page before the next article at the
+// same or a higher level starts. The last article ends at the last page, or before the page
+// starting with endsWith.
+func articleEndPage(articles []models.Article, articlePages []int, i int, endsWith string, pageContents []string) (int, error) {
+	article, startPage, totalPages := articles[i], articlePages[i], len(pageContents)
+	if article.End > 0 {
+		if article.End > totalPages {
+			return 0, fmt.Errorf("end %d of article '%s' is past the last page %d", article.End, article.Title, totalPages)
+		}
+		if article.End < startPage {
+			return 0, fmt.Errorf("end %d of article '%s' comes before page %d, where it was found to start: set its start too", article.End, article.Title, startPage)
+		}
+		return article.End, nil
+	}
+	if article.EndsWith != "" {
+		// The article cannot end before its own first page
+		pageFound, err := findPageEndingWith(article.EndsWith, min(startPage+1, totalPages), totalPages, pageContents)
+		if err != nil {
+			return 0, fmt.Errorf("error finding page for endsWith of article '%s': %v", article.Title, err)
+		}
+		if pageFound > startPage {
+			return pageFound - 1, nil
+		}
+		logrus.Warnf("No page after page %d starts with '%s', the endsWith of article '%s'", startPage, article.EndsWith, article.Title)
+	}
 
-	// Find prefix from the reverse order
-	reversedStrings := make([]string, len(processedStrings))
-	copy(reversedStrings, processedStrings)
-	for i := 0; i < len(reversedStrings)/2; i++ {
-		reversedStrings[i], reversedStrings[len(reversedStrings)-1-i] = reversedStrings[len(reversedStrings)-1-i], reversedStrings[i]
+	// The article ends where the next one at the same or a higher outline level starts
+	next := i + 1
+	for next < len(articles) && article.Level > 0 && articles[next].Level > article.Level {
+		next++
+	}
+	if next < len(articles) {
+		if nextStartPage := articlePages[next]; nextStartPage > 0 {
+			// Articles sharing a page both keep it
+			return max(nextStartPage-1, startPage), nil
+		}
+		return totalPages, nil
 	}
-	endPrefix := findPrefix(reversedStrings)
 
-	// Return the longer of the two prefixes
-	if len(startPrefix) > len(endPrefix) {
-		return startPrefix, nil
+	// Handle the last article
+	endPage := totalPages
+	if endsWith != "" {
+		pageFound, err := findPageEndingWith(endsWith, startPage, totalPages, pageContents)
+		if err != nil {
+			return 0, fmt.Errorf("error finding page for --ends-with: %v", err)
+		} else if pageFound > 0 {
+			endPage = pageFound - 1
+		}
 	}
-	return endPrefix, nil
+	return endPage, nil
 }
 
-// startsWith checks if a string starts with the given prefix.
-func stringStartsWith(str, prefix string) bool {
-	return len(str) >= len(prefix) && str[:len(prefix)] == prefix
-}
+// minOffsetVotes is how many articles must agree on the page offset before it is trusted
+const minOffsetVotes = 2
 
-// removePrefix removes the identified prefix from the original strings.
-func removePrefix(stringsList []string, prefix string) []string {
-	result := []string{}
-
-	for _, str := range stringsList {
-		// Remove digits from the string to check if the prefix exists
-		processedStr := utils.RemoveDigits(str)
-		if stringStartsWith(processedStr, prefix) {
-			// Iterate through the string and remove characters until the length of the prefix is reached
-			prefixLength := len(prefix)
-			removedLength := 0
-			for i := 0; i < len(str); i++ {
-				if removedLength >= prefixLength {
-					str = str[i:]
-					break
-				}
-				if !utils.IsDigit(str[i]) {
-					removedLength++
-				}
-			}
-			// If the length of the prefix is not reached, don't remove any characters
-			if removedLength < prefixLength {
-				str = stringsList[len(result)] // Restore original string
-			}
+// detectPageOffset works out how far physical pages are ahead of the printed page numbers
+// listed in the contents, from the articles whose titles were found. More than half of them,
+// and at least minOffsetVotes, must agree on the offset; otherwise every article keeps the
+// page its title was found on.
+func detectPageOffset(articles []models.Article, articlePages []int) (int, bool) {
+	votes := map[int]int{}
+	checked := 0
+	for i, article := range articles {
+		page := articlePages[i]
+		if article.Page == 0 || page == 0 {
+			continue
 		}
-		result = append(result, str)
+		votes[page-article.Page]++
+		checked++
 	}
-
-	return result
+	offset, best := 0, 0
+	for o, n := range votes {
+		if n > best || (n == best && o < offset) {
+			offset, best = o, n
+		}
+	}
+	if best < minOffsetVotes || best*2 <= checked {
+		return 0, false
+	}
+	logrus.Infof("Physical pages are %d ahead of the printed page numbers (agreed by %d of %d articles found)", offset, best, checked)
+	return offset, true
 }
+
 func matchArticleTitleByLength(content, article string) bool {
 	// Normalize both the content and the article title
 	normalizedContent := utils.NormalizeText(content)
 	normalizedArticle := utils.NormalizeText(article)
 
+	// A title without letters or digits would match every page
+	if normalizedArticle == "" {
+		return false
+	}
+
 	// Ensure the content is long enough to compare
 	if len(normalizedContent) < len(normalizedArticle) {
 		return false
@@ -316,20 +444,11 @@ func matchArticleTitleByLength(content, article string) bool {
 	// Compare the normalized article title with a substring of the normalized content
 	return strings.HasPrefix(normalizedContent, normalizedArticle)
 }
-func extractPDFPages(pdfPath, chapterOutputPath string, startPage, endPage int) error {
-	// Run the pdftk command to extract pages
-	cmd := exec.Command("pdftk", pdfPath, "cat", fmt.Sprintf("%d-%d", startPage, endPage), "output", chapterOutputPath)
-	cmd.Stdout = nil
-	cmd.Stderr = nil
-
-	err := cmd.Run()
-	if err != nil {
-		return fmt.Errorf("failed to extract pages using pdftk: %v", err)
+func findPageEndingWith(endsWith string, startPage, totalPages int, pageContents []string) (int, error) {
+	normalizedEndsWith := utils.NormalizeText(endsWith)
+	if normalizedEndsWith == "" {
+		return 0, fmt.Errorf("--ends-with '%s' contains no letters or digits to match", endsWith)
 	}
-
-	return nil
-}
-func findPageEndingWith(pdfPath, endsWith string, startPage, totalPages int, pageContents []string) (int, error) {
 	for page := startPage; page <= totalPages; page++ {
 		// Check if the pageContents slice has enough elements
 		if page-1 >= len(pageContents) {
@@ -341,7 +460,7 @@ func findPageEndingWith(pdfPath, endsWith string, startPage, totalPages int, pag
 		logrus.Debugf("[DEBUG] Normalized content of page %d: '%s'", page, normalizedContent[:min(len(normalizedContent), 50)])
 
 		// Check if the content ends with the specified text
-		if strings.HasPrefix(normalizedContent, utils.NormalizeText(endsWith)) {
+		if strings.HasPrefix(normalizedContent, normalizedEndsWith) {
 			logrus.Debugf("[DEBUG] Found 'ends-with' match on page %d: '%s'", page, normalizedContent[:min(len(normalizedContent), 50)])
 			return page, nil
 		}
@@ -0,0 +1,330 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"pdf-extractor/internal/models"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestDetectPageOffset(t *testing.T) {
+	tests := []struct {
+		name         string
+		printed      []int
+		articlePages []int
+		wantOffset   int
+		wantOK       bool
+	}{
+		{
+			name:         "all articles agree",
+			printed:      []int{1, 9, 20},
+			articlePages: []int{5, 13, 24},
+			wantOffset:   4,
+			wantOK:       true,
+		},
+		{
+			name:         "majority of two",
+			printed:      []int{1, 9, 20},
+			articlePages: []int{5, 13, 30},
+			wantOffset:   4,
+			wantOK:       true,
+		},
+		{
+			name:         "negative offset",
+			printed:      []int{10, 20},
+			articlePages: []int{8, 18},
+			wantOffset:   -2,
+			wantOK:       true,
+		},
+		{
+			name:         "single vote",
+			printed:      []int{1, 9},
+			articlePages: []int{5, 0},
+		},
+		{
+			name:         "tie of one against one",
+			printed:      []int{1, 9},
+			articlePages: []int{5, 15},
+		},
+		{
+			name:         "tie of two against two",
+			printed:      []int{1, 5, 9, 13},
+			articlePages: []int{5, 9, 15, 19},
+		},
+		{
+			name:         "largest vote without a majority",
+			printed:      []int{1, 5, 9, 13, 17},
+			articlePages: []int{5, 9, 15, 20, 25},
+		},
+		{
+			name:         "articles without a printed page are not counted",
+			printed:      []int{0, 0, 3, 7},
+			articlePages: []int{2, 4, 5, 9},
+			wantOffset:   2,
+			wantOK:       true,
+		},
+		{
+			name: "nothing found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			articles := make([]models.Article, len(tt.printed))
+			for i, page := range tt.printed {
+				articles[i] = models.Article{Title: "Article", Page: page}
+			}
+			offset, ok := detectPageOffset(articles, tt.articlePages)
+			if offset != tt.wantOffset || ok != tt.wantOK {
+				t.Errorf("detectPageOffset() = %d, %v, want %d, %v", offset, ok, tt.wantOffset, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestArticleEndPage(t *testing.T) {
+	pages := []string{
+		"Rivers of the Plains\nA. Kumar",
+		"The rivers",
+		"Notes\nThe plains",
+		"Lakes\nB. Singh",
+		"Lakes are",
+		"Index",
+	}
+	tests := []struct {
+		name         string
+		articles     []models.Article
+		articlePages []int
+		i            int
+		endsWith     string
+		want         int
+		wantErr      bool
+	}{
+		{
+			name:         "before the next article",
+			articles:     []models.Article{{Title: "Rivers of the Plains"}, {Title: "Lakes"}},
+			articlePages: []int{1, 4},
+			want:         3,
+		},
+		{
+			name:         "end",
+			articles:     []models.Article{{Title: "Rivers of the Plains", End: 2}, {Title: "Lakes"}},
+			articlePages: []int{1, 4},
+			want:         2,
+		},
+		{
+			name:         "end past the last page",
+			articles:     []models.Article{{Title: "Rivers of the Plains", End: 7}},
+			articlePages: []int{1},
+			wantErr:      true,
+		},
+		{
+			name:         "end before the start found",
+			articles:     []models.Article{{Title: "Rivers of the Plains"}, {Title: "Lakes", End: 2}},
+			articlePages: []int{1, 4},
+			i:            1,
+			wantErr:      true,
+		},
+		{
+			name:         "endsWith",
+			articles:     []models.Article{{Title: "Rivers of the Plains", EndsWith: "Notes"}, {Title: "Lakes"}},
+			articlePages: []int{1, 4},
+			want:         2,
+		},
+		{
+			name:         "endsWith not found",
+			articles:     []models.Article{{Title: "Rivers of the Plains", EndsWith: "Seas"}, {Title: "Lakes"}},
+			articlePages: []int{1, 4},
+			want:         3,
+		},
+		{
+			name:         "a skipped article still ends the one before",
+			articles:     []models.Article{{Title: "Rivers of the Plains"}, {Title: "Notes", Skip: true}, {Title: "Lakes"}},
+			articlePages: []int{1, 3, 4},
+			want:         2,
+		},
+		{
+			name:         "last article",
+			articles:     []models.Article{{Title: "Rivers of the Plains"}, {Title: "Lakes"}},
+			articlePages: []int{1, 4},
+			i:            1,
+			want:         6,
+		},
+		{
+			name:         "last article with --ends-with",
+			articles:     []models.Article{{Title: "Rivers of the Plains"}, {Title: "Lakes"}},
+			articlePages: []int{1, 4},
+			i:            1,
+			endsWith:     "Index",
+			want:         5,
+		},
+	}
+	contents, _ := normalizedPages(pages)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := articleEndPage(tt.articles, tt.articlePages, tt.i, tt.endsWith, contents)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("articleEndPage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("articleEndPage() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateOverrides(t *testing.T) {
+	tests := []struct {
+		name    string
+		article models.Article
+		wantErr bool
+	}{
+		{name: "no overrides", article: models.Article{Title: "Lakes"}},
+		{name: "start and end", article: models.Article{Title: "Lakes", Start: 4, End: 5, Filename: "lakes.pdf"}},
+		{name: "negative start", article: models.Article{Title: "Lakes", Start: -1}, wantErr: true},
+		{name: "negative end", article: models.Article{Title: "Lakes", End: -1}, wantErr: true},
+		{name: "end before start", article: models.Article{Title: "Lakes", Start: 5, End: 4}, wantErr: true},
+		{name: "filename with a directory", article: models.Article{Title: "Lakes", Filename: "out/lakes.pdf"}, wantErr: true},
+		{name: "filename leaving the output directory", article: models.Article{Title: "Lakes", Filename: "../lakes.pdf"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateOverrides(tt.article); (err != nil) != tt.wantErr {
+				t.Errorf("validateOverrides() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReadArticlesFromConfig(t *testing.T) {
+	config := `articles:
+  - title: Rivers of the Plains
+    endWith: Notes
+  - title: "***"
+    start: 4
+    filename: lakes.pdf
+  - title: "---"
+`
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	articles, _, err := readArticlesFromConfig(path)
+	if err != nil {
+		t.Fatalf("readArticlesFromConfig() error = %v", err)
+	}
+	// The unknown key is ignored, and the title without letters is kept only with a start
+	want := []models.Article{{Title: "Rivers of the Plains"}, {Title: "***", Start: 4, Filename: "lakes.pdf"}}
+	if !reflect.DeepEqual(articles, want) {
+		t.Errorf("readArticlesFromConfig() = %+v, want %+v", articles, want)
+	}
+}
+
+// stubExtractor returns the given text for the pages of any PDF.
+type stubExtractor struct {
+	pages []string
+}
+
+func (e *stubExtractor) Name() string { return "stub" }
+
+func (e *stubExtractor) PageCount(pdfPath string) (int, error) { return len(e.pages), nil }
+
+func (e *stubExtractor) ExtractPage(pdfPath string, page int) (string, error) {
+	return e.pages[page-1], nil
+}
+
+func (e *stubExtractor) ExtractPages(pdfPath string) ([]string, error) { return e.pages, nil }
+
+// recordingWriter records the pages it is asked to write instead of writing them.
+type recordingWriter struct {
+	mu      sync.Mutex
+	written []string
+}
+
+func (w *recordingWriter) Name() string { return "recording" }
+
+func (w *recordingWriter) ExtractPages(src, dst string, from, to int) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.written = append(w.written, fmt.Sprintf("%s %d-%d", dst, from, to))
+	return nil
+}
+
+func (w *recordingWriter) RemovePages(src string, from, to int) error {
+	return fmt.Errorf("not supported")
+}
+
+// journalPages are the pages of an issue with an editorial and two sections.
+var journalPages = []string{
+	"Editorial\nThis issue",
+	"Rivers of the Plains\nA. Kumar",
+	"The rivers",
+	"Lakes\nB. Singh",
+	"A Survey of Wells\nC. Rao",
+	"The wells",
+}
+
+func TestExtractPagesForArticlesSections(t *testing.T) {
+	articles := []models.Article{
+		{Title: "Editorial"},
+		{Title: "Rivers of the Plains", Section: "Research Articles"},
+		{Title: "Lakes", Section: "Research Articles"},
+		{Title: "A Survey of Wells", Section: "Book Reviews: 2024"},
+	}
+	tests := []struct {
+		slug string
+		want []string
+	}{
+		{
+			slug: SlugUnderscore,
+			want: []string{
+				"Book_Reviews_2024/A_Survey_of_Wells.pdf 5-6",
+				"Editorial.pdf 1-1",
+				"Research_Articles/Lakes.pdf 4-4",
+				"Research_Articles/Rivers_of_the_Plains.pdf 2-3",
+			},
+		},
+		{
+			slug: SlugHyphen,
+			want: []string{
+				"book-reviews-2024/a-survey-of-wells.pdf 5-6",
+				"editorial.pdf 1-1",
+				"research-articles/lakes.pdf 4-4",
+				"research-articles/rivers-of-the-plains.pdf 2-3",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.slug, func(t *testing.T) {
+			outputPath := t.TempDir()
+			names, err := newNameTemplate(DefaultNameTemplate, tt.slug)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pw := &recordingWriter{}
+			err = extractPagesForArticles(&stubExtractor{pages: journalPages}, pw, "journal.pdf", articles, outputPath, "", nil, DefaultHeaderThreshold, DefaultMatchThreshold, 2, names, "")
+			if err != nil {
+				t.Fatalf("extractPagesForArticles() error = %v", err)
+			}
+			var got []string
+			for _, w := range pw.written {
+				got = append(got, filepath.ToSlash(strings.TrimPrefix(w, outputPath+string(filepath.Separator))))
+			}
+			slices.Sort(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("written = %q, want %q", got, tt.want)
+			}
+			// The directory of every section is created
+			for _, w := range tt.want {
+				dir := filepath.Join(outputPath, filepath.Dir(strings.Fields(w)[0]))
+				if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+					t.Errorf("directory %s was not created: %v", dir, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"pdf-extractor/internal/utils"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultHeaderThreshold is the share of odd or even pages a line must repeat on to count as a running header or footer
+	DefaultHeaderThreshold = 0.6
+	// headerLines is how many lines at the top and bottom of each page are checked for running headers and footers
+	headerLines = 3
+)
+
+// romanPageRegex matches page numbers in roman numerals, as used for front matter.
+var romanPageRegex = regexp.MustCompile(`^(?i)[ivxlc]+$`)
+
+// edgeLine is a line near the top or bottom of a page.
+type edgeLine struct {
+	index int  // index of the line on the page
+	top   bool // whether the line is at the top rather than the bottom of the page
+	pos   int  // distance in lines from the top or bottom
+	key   string
+}
+
+// headerKey identifies a line regardless of the page numbers in it.
+func headerKey(line string) string {
+	key := strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return -1
+		}
+		return r
+	}, utils.NormalizeText(line))
+	if (key == "" && strings.IndexFunc(line, unicode.IsDigit) >= 0) || romanPageRegex.MatchString(strings.TrimSpace(line)) {
+		// A bare page number
+		return "#"
+	}
+	return key
+}
+
+// edgeLines returns the first and last headerLines non-empty lines of a page.
+func edgeLines(lines []string) []edgeLine {
+	var nonEmpty []int
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			nonEmpty = append(nonEmpty, i)
+		}
+	}
+	var edges []edgeLine
+	for n, i := range nonEmpty {
+		if n < headerLines || n >= len(nonEmpty)-headerLines {
+			if key := headerKey(lines[i]); key != "" {
+				e := edgeLine{index: i, top: n < headerLines, pos: n, key: key}
+				if !e.top {
+					e.pos = len(nonEmpty) - 1 - n
+				}
+				edges = append(edges, e)
+			}
+		}
+	}
+	return edges
+}
+
+// removeRunningHeaders returns the text of the pages without their running headers and footers.
+//
+// Odd and even pages are looked at separately, since books and journals often alternate
+// headers. A line near the top or bottom of a page is removed when it repeats on at least
+// threshold of the pages with the same parity, or when it also appeared at the same position on
+// the previous page with the same parity, which catches headers that carry the title of the current
+// article while keeping the title itself on the first page of the article.
+func removeRunningHeaders(pages []string, threshold float64) []string {
+	lines := make([][]string, len(pages))
+	edges := make([][]edgeLine, len(pages))
+	for i, content := range pages {
+		lines[i] = strings.Split(content, "\n")
+		edges[i] = edgeLines(lines[i])
+	}
+
+	// Count the pages of each parity every edge line appears on
+	var pagesOfParity [2]int
+	counts := [2]map[string]int{{}, {}}
+	for i := range pages {
+		parity := i % 2
+		pagesOfParity[parity]++
+		seen := map[string]bool{}
+		for _, e := range edges[i] {
+			if !seen[e.key] {
+				seen[e.key] = true
+				counts[parity][e.key]++
+			}
+		}
+	}
+
+	cleaned := make([]string, len(pages))
+	for i := range pages {
+		parity := i % 2
+		previous := map[edgeLine]bool{}
+		if i >= 2 {
+			for _, e := range edges[i-2] {
+				previous[edgeLine{top: e.top, pos: e.pos, key: e.key}] = true
+			}
+		}
+
+		remove := map[int]bool{}
+		for _, e := range edges[i] {
+			count := counts[parity][e.key]
+			repeated := count >= 2 && float64(count) >= threshold*float64(pagesOfParity[parity])
+			if repeated || previous[edgeLine{top: e.top, pos: e.pos, key: e.key}] {
+				logrus.Debugf("Removing running header or footer from page %d: '%s'", i+1, strings.TrimSpace(lines[i][e.index]))
+				remove[e.index] = true
+			}
+		}
+
+		var b strings.Builder
+		for j, line := range lines[i] {
+			if !remove[j] {
+				b.WriteString(line)
+				b.WriteString("\n")
+			}
+		}
+		cleaned[i] = b.String()
+	}
+	return cleaned
+}
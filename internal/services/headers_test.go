@@ -0,0 +1,135 @@
+package services
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHeaderKey(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"Journal of Hydrology 12", "journalofhydrology"},
+		{"Page 4 of 20", "pageof"},
+		{"  17 ", "#"},
+		{"xiv", "#"},
+		{"– 3 –", "#"},
+		{"---", ""},
+	}
+	for _, tt := range tests {
+		if got := headerKey(tt.line); got != tt.want {
+			t.Errorf("headerKey(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestRemoveRunningHeaders(t *testing.T) {
+	tests := []struct {
+		name      string
+		pages     []string
+		threshold float64
+		want      [][]string
+	}{
+		{
+			name: "header and page numbers on every page",
+			pages: []string{
+				"Journal of Hydrology\nFirst page text\n1",
+				"Journal of Hydrology\nSecond page text\n2",
+				"Journal of Hydrology\nThird page text\n3",
+				"Journal of Hydrology\nFourth page text\n4",
+			},
+			threshold: DefaultHeaderThreshold,
+			want:      [][]string{{"First page text"}, {"Second page text"}, {"Third page text"}, {"Fourth page text"}},
+		},
+		{
+			name: "alternating headers",
+			pages: []string{
+				"Vol. 3\nText one",
+				"Water Management\nText two",
+				"Vol. 3\nText three",
+				"Water Management\nText four",
+			},
+			threshold: DefaultHeaderThreshold,
+			want:      [][]string{{"Text one"}, {"Text two"}, {"Text three"}, {"Text four"}},
+		},
+		{
+			name: "article title kept on its first page",
+			pages: []string{
+				"Rivers of the Plains\nIntroduction",
+				"Journal\nText one",
+				"Rivers of the Plains\nMethods",
+				"Journal\nText two",
+				"Lakes\nAbstract",
+				"Journal\nText three",
+				"Lakes\nResults",
+				"Journal\nText four",
+			},
+			threshold: DefaultHeaderThreshold,
+			want: [][]string{
+				{"Rivers of the Plains", "Introduction"}, {"Text one"}, {"Methods"}, {"Text two"},
+				{"Lakes", "Abstract"}, {"Text three"}, {"Results"}, {"Text four"},
+			},
+		},
+		{
+			name: "lines below the threshold are kept",
+			pages: []string{
+				"Notes\nText one",
+				"A\nText two",
+				"Other\nText three",
+				"B\nText four",
+				"Another\nText five",
+				"C\nText six",
+				"Notes\nText seven",
+			},
+			threshold: DefaultHeaderThreshold,
+			want: [][]string{
+				{"Notes", "Text one"}, {"A", "Text two"}, {"Other", "Text three"}, {"B", "Text four"},
+				{"Another", "Text five"}, {"C", "Text six"}, {"Notes", "Text seven"},
+			},
+		},
+		{
+			name: "lines in the middle of the page are kept",
+			pages: []string{
+				"a\nb\nc\nRepeated\nd\ne\nf",
+				"g\nh\ni\nRepeated\nj\nk\nl",
+				"m\nn\no\nRepeated\np\nq\nr",
+			},
+			threshold: DefaultHeaderThreshold,
+			want: [][]string{
+				{"a", "b", "c", "Repeated", "d", "e", "f"},
+				{"g", "h", "i", "Repeated", "j", "k", "l"},
+				{"m", "n", "o", "Repeated", "p", "q", "r"},
+			},
+		},
+		{
+			name:      "single page",
+			pages:     []string{"Title\nText\n1"},
+			threshold: DefaultHeaderThreshold,
+			want:      [][]string{{"Title", "Text", "1"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got [][]string
+			for _, page := range removeRunningHeaders(tt.pages, tt.threshold) {
+				got = append(got, pageLines(page))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("removeRunningHeaders() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// pageLines returns the non-empty lines of a page.
+func pageLines(page string) []string {
+	var lines []string
+	for _, line := range strings.Split(page, "\n") {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"pdf-extractor/internal/toc"
+	"pdf-extractor/internal/utils"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// lowConfidence is the confidence below which entries of the index are flagged for review
+	lowConfidence = 0.75
+	// longTitleWords is the number of words above which a title has probably swallowed the next entry
+	longTitleWords = 20
+	// longAuthorWords is the number of words above which an author has probably swallowed part of the title
+	longAuthorWords = 6
+)
+
+// indexReport is the review of the entries written to config.yaml, saved to index-report.json.
+type indexReport struct {
+	File    string        `json:"file"`
+	Source  string        `json:"source"`
+	Layout  string        `json:"layout,omitempty"`
+	Entries []entryReview `json:"entries"`
+}
+
+// entryReview is how sure extract-index is that it read an entry correctly, and why.
+type entryReview struct {
+	Title      string   `json:"title"`
+	Number     string   `json:"number,omitempty"`
+	Page       int      `json:"page,omitempty"`
+	Confidence float64  `json:"confidence"`
+	Warnings   []string `json:"warnings,omitempty"`
+}
+
+// reviewEntries scores every entry between 0 and 1, lowering the score for each sign that it
+// was read wrong. Entries read from the outline are not expected to list authors or pages.
+func reviewEntries(entries []toc.Entry, fromOutline bool) []entryReview {
+	// Authors and pages are only expected when most entries have them, as in a journal
+	withAuthors, withPages := 0, 0
+	for _, e := range entries {
+		if len(e.Authors) > 0 {
+			withAuthors++
+		}
+		if e.Page > 0 {
+			withPages++
+		}
+	}
+	expectAuthors := !fromOutline && withAuthors*2 > len(entries)
+	expectPages := !fromOutline && withPages*2 > len(entries)
+
+	reviews := make([]entryReview, len(entries))
+	// Numbering may start again in every section
+	lastNumbers := map[[2]string]toc.Entry{}
+	lastPage := 0
+	for i, e := range entries {
+		confidence := 1.0
+		var warnings []string
+		warn := func(penalty float64, format string, args ...interface{}) {
+			confidence -= penalty
+			warnings = append(warnings, fmt.Sprintf(format, args...))
+		}
+
+		if utils.NormalizeText(e.Title) == "" {
+			warn(0.6, "no title detected")
+		}
+		if e.TitleLines > 2 {
+			warn(0.15*float64(e.TitleLines-2), "title merged across %d lines", e.TitleLines)
+		}
+		if words := len(strings.Fields(e.Title)); words > longTitleWords {
+			warn(0.2, "title is unusually long (%d words)", words)
+		}
+		if expectAuthors && len(e.Authors) == 0 {
+			warn(0.3, "no author detected")
+		}
+		for _, author := range e.Authors {
+			if words := len(strings.Fields(author.Name)); words > longAuthorWords {
+				warn(0.2, "author '%s' looks like part of the title", author.Name)
+			}
+		}
+		if expectPages && e.Page == 0 && e.PhysicalPage == 0 {
+			warn(0.1, "no page number")
+		}
+		if e.Page > 0 {
+			if e.Page < lastPage {
+				warn(0.4, "page %d comes before page %d of the previous entry", e.Page, lastPage)
+			}
+			lastPage = e.Page
+		}
+		if sequence, n, ok := toc.Sequence(e.Number); e.Number != "" && ok {
+			key := [2]string{e.Section, sequence}
+			if previous, seen := lastNumbers[key]; seen {
+				_, last, _ := toc.Sequence(previous.Number)
+				switch {
+				case n > last+1:
+					warn(0.4, "numbering gap between %s and %s", previous.Number, e.Number)
+				case n <= last:
+					warn(0.4, "numbering goes back from %s to %s", previous.Number, e.Number)
+				}
+			}
+			lastNumbers[key] = e
+		}
+
+		reviews[i] = entryReview{
+			Title:      e.Title,
+			Number:     e.Number,
+			Page:       e.Page,
+			Confidence: math.Round(math.Max(confidence, 0)*100) / 100,
+			Warnings:   warnings,
+		}
+	}
+	return reviews
+}
+
+// saveIndexReport writes the review of the entries as JSON.
+func saveIndexReport(report indexReport, filePath string) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode index report: %v", err)
+	}
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write index report: %v", err)
+	}
+	return nil
+}
+
+// printLowConfidence lists the entries that should be checked by hand.
+func printLowConfidence(reviews []entryReview, reportPath string) {
+	low := 0
+	for i, r := range reviews {
+		if r.Confidence < lowConfidence {
+			logrus.Warnf("Check entry %d '%s' (confidence %.2f): %s", i+1, r.Title, r.Confidence, strings.Join(r.Warnings, "; "))
+			low++
+		}
+	}
+	if low > 0 {
+		fmt.Printf("%d of %d entries have a confidence below %.2f, see %s\n", low, len(reviews), lowConfidence, reportPath)
+	} else {
+		fmt.Printf("None of the %d entries need checking, see %s\n", len(reviews), reportPath)
+	}
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"pdf-extractor/internal/models"
+	"pdf-extractor/internal/toc"
+	"reflect"
+	"testing"
+)
+
+// tocEntry returns an entry numbered number with a title, an author and a page.
+func tocEntry(number, title, author string, page int) toc.Entry {
+	e := toc.Entry{Article: models.Article{Number: number, Title: title, Page: page}, TitleLines: 1}
+	if author != "" {
+		e.Authors = []models.Author{{Name: author}}
+	}
+	return e
+}
+
+func TestReviewEntries(t *testing.T) {
+	tests := []struct {
+		name        string
+		entries     []toc.Entry
+		fromOutline bool
+		want        [][]string
+	}{
+		{
+			name: "no warnings",
+			entries: []toc.Entry{
+				tocEntry("1", "Rivers", "A. Kumar", 3),
+				tocEntry("2", "Lakes", "B. Singh", 9),
+			},
+			want: [][]string{nil, nil},
+		},
+		{
+			name: "numbering gap",
+			entries: []toc.Entry{
+				tocEntry("7", "Rivers", "A. Kumar", 3),
+				tocEntry("9", "Lakes", "B. Singh", 9),
+			},
+			want: [][]string{nil, {"numbering gap between 7 and 9"}},
+		},
+		{
+			name: "numbering goes back",
+			entries: []toc.Entry{
+				tocEntry("2", "Rivers", "A. Kumar", 3),
+				tocEntry("1", "Lakes", "B. Singh", 9),
+			},
+			want: [][]string{nil, {"numbering goes back from 2 to 1"}},
+		},
+		{
+			name: "title merged across lines",
+			entries: []toc.Entry{
+				{Article: models.Article{Title: "Rivers of the Plains and Their Floods", Page: 3}, TitleLines: 4},
+			},
+			want: [][]string{{"title merged across 4 lines"}},
+		},
+		{
+			name: "no author detected when most entries have one",
+			entries: []toc.Entry{
+				tocEntry("1", "Rivers", "A. Kumar", 3),
+				tocEntry("2", "Lakes", "", 9),
+				tocEntry("3", "Seas", "C. Rao", 15),
+			},
+			want: [][]string{nil, {"no author detected"}, nil},
+		},
+		{
+			name: "no author expected when most entries have none",
+			entries: []toc.Entry{
+				tocEntry("1", "Rivers", "A. Kumar", 3),
+				tocEntry("2", "Lakes", "", 9),
+				tocEntry("3", "Seas", "", 15),
+			},
+			want: [][]string{nil, nil, nil},
+		},
+		{
+			name: "no author expected from the outline",
+			entries: []toc.Entry{
+				tocEntry("1", "Rivers", "A. Kumar", 3),
+				tocEntry("2", "Lakes", "", 9),
+				tocEntry("3", "Seas", "C. Rao", 15),
+			},
+			fromOutline: true,
+			want:        [][]string{nil, nil, nil},
+		},
+		{
+			name: "page goes backwards",
+			entries: []toc.Entry{
+				tocEntry("", "Rivers", "A. Kumar", 15),
+				tocEntry("", "Lakes", "B. Singh", 12),
+			},
+			want: [][]string{nil, {"page 12 comes before page 15 of the previous entry"}},
+		},
+		{
+			name: "numbering starts again in every section",
+			entries: []toc.Entry{
+				{Article: models.Article{Number: "1", Title: "Rivers", Page: 3, Section: "Articles"}},
+				{Article: models.Article{Number: "1", Title: "Lakes", Page: 9, Section: "Book Reviews"}},
+			},
+			want: [][]string{nil, nil},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got [][]string
+			for _, review := range reviewEntries(tt.entries, tt.fromOutline) {
+				got = append(got, review.Warnings)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("reviewEntries() warnings = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReviewEntriesConfidence(t *testing.T) {
+	reviews := reviewEntries([]toc.Entry{
+		tocEntry("7", "Rivers", "A. Kumar", 3),
+		tocEntry("9", "Lakes", "B. Singh", 9),
+	}, false)
+	if reviews[0].Confidence != 1 || reviews[1].Confidence != 0.6 {
+		t.Errorf("confidences = %v and %v, want 1 and 0.6", reviews[0].Confidence, reviews[1].Confidence)
+	}
+	if reviews[1].Confidence >= lowConfidence {
+		t.Errorf("an entry after a numbering gap is not flagged")
+	}
+}
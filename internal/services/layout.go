@@ -0,0 +1,123 @@
+package services
+
+import (
+	"pdf-extractor/internal/extractor"
+	"pdf-extractor/internal/utils"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// headingScale is how much larger than the body text a line must be set to count as a heading
+	headingScale = 1.15
+	// headingArea is the share of the page height, from the top, in which headings are looked for
+	headingArea = 0.5
+	// headingTopLines is how many lines at the top of a page headings are looked for in when the layout is unknown
+	headingTopLines = 8
+)
+
+// loadPagesWithLayout returns the text and the layout of every page in the PDF. When the
+// extractor reports the layout the document is extracted once and the text read from the
+// layout; otherwise, or when that fails, the layout is nil.
+func loadPagesWithLayout(ext extractor.TextExtractor, pdfPath string) ([]string, []extractor.Page, error) {
+	if l, ok := ext.(extractor.LayoutExtractor); ok {
+		layout, err := l.ExtractLayout(pdfPath)
+		if err == nil {
+			pages := make([]string, len(layout))
+			for i, page := range layout {
+				pages[i] = page.Text()
+			}
+			return pages, layout, nil
+		}
+		logrus.Warnf("Failed to extract page layout, falling back to plain text: %v", err)
+	}
+	pages, _, err := loadPages(ext, pdfPath)
+	return pages, nil, err
+}
+
+// headingLines returns the normalized text of the lines near the top of the page
+// that are set larger than its body text.
+func headingLines(page extractor.Page) []string {
+	body := page.BodyFontSize()
+	var lines []string
+	for _, l := range page.Lines {
+		if l.FontSize < body*headingScale || (page.Height > 0 && l.YMin > page.Height*headingArea) {
+			continue
+		}
+		if text := utils.NormalizeText(l.Text); text != "" {
+			lines = append(lines, text)
+		}
+	}
+	return lines
+}
+
+// titleHeadingPages returns the pages with a heading that starts with the title.
+func titleHeadingPages(layout []extractor.Page, title string) []int {
+	normalizedTitle := utils.NormalizeText(title)
+	if normalizedTitle == "" {
+		return nil
+	}
+	var pages []int
+	for i, page := range layout {
+		lines := headingLines(page)
+		for j := range lines {
+			// Long titles wrap over several heading lines
+			if strings.HasPrefix(strings.Join(lines[j:], ""), normalizedTitle) {
+				pages = append(pages, i+1)
+				break
+			}
+		}
+	}
+	return pages
+}
+
+// findHeadingPage returns the first page with a line near its top that isHeading accepts, or 0.
+func findHeadingPage(layout []extractor.Page, isHeading func(line string) bool) int {
+	for i, page := range layout {
+		for _, l := range page.Lines {
+			if page.Height > 0 && l.YMin > page.Height*headingArea {
+				break
+			}
+			if isHeading(l.Text) {
+				return i + 1
+			}
+		}
+	}
+	return 0
+}
+
+// readColumns returns the text of a page set in columns, reading each column from top to
+// bottom. Lines spanning several columns, such as headings and footers, are kept above or
+// below the columns.
+func readColumns(page extractor.Page, columns int) string {
+	if page.Width <= 0 || columns < 2 {
+		return page.Text()
+	}
+	width := page.Width / float64(columns)
+	column := func(x float64) int {
+		return min(max(int(x/width), 0), columns-1)
+	}
+
+	top := extractor.Page{}
+	bottom := extractor.Page{}
+	cols := make([]extractor.Page, columns)
+	inColumns := false
+	for _, l := range page.Lines {
+		if c := column(l.XMin); c == column(l.XMax-1) {
+			cols[c].Lines = append(cols[c].Lines, l)
+			inColumns = true
+		} else if !inColumns {
+			top.Lines = append(top.Lines, l)
+		} else {
+			bottom.Lines = append(bottom.Lines, l)
+		}
+	}
+	var b strings.Builder
+	b.WriteString(top.Text())
+	for _, c := range cols {
+		b.WriteString(c.Text())
+	}
+	b.WriteString(bottom.Text())
+	return b.String()
+}
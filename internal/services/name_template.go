@@ -0,0 +1,150 @@
+package services
+
+import (
+	"fmt"
+	"path/filepath"
+	"pdf-extractor/internal/models"
+	"pdf-extractor/internal/utils"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// DefaultNameTemplate names the PDF of an article after its title
+	DefaultNameTemplate = "{title}"
+
+	SlugUnderscore = "underscore"
+	SlugHyphen     = "hyphen"
+	SlugKeep       = "keep"
+)
+
+// placeholderRegex matches a placeholder of a name template, such as {title} or {index:02}
+var placeholderRegex = regexp.MustCompile(`\{(\w+)(?::(\d+))?\}`)
+
+// placeholders are the values a name template can use, and whether they are numbers
+var placeholders = map[string]bool{
+	"index":   true,
+	"start":   true,
+	"end":     true,
+	"title":   false,
+	"author":  false,
+	"number":  false,
+	"section": false,
+	"source":  false,
+}
+
+// nameTemplate builds the file names of the extracted articles from a template such as
+// "{index:02}-{title}". Text values are turned into slugs in the given style, and a width
+// after the colon pads numbers with zeros ("{index:02}") or shortens text ("{title:40}").
+type nameTemplate struct {
+	template string
+	slug     string
+}
+
+// nameValues are the values of the placeholders for one article.
+type nameValues struct {
+	index      int
+	article    models.Article
+	start, end int
+	source     string
+}
+
+// newNameTemplate checks the placeholders of the template and the slug style.
+func newNameTemplate(template string, slug string) (*nameTemplate, error) {
+	if slug != SlugUnderscore && slug != SlugHyphen && slug != SlugKeep {
+		return nil, fmt.Errorf("unknown slug style '%s': expected '%s', '%s' or '%s'", slug, SlugUnderscore, SlugHyphen, SlugKeep)
+	}
+	if strings.TrimSpace(template) == "" {
+		return nil, fmt.Errorf("--name-template must not be empty")
+	}
+	for _, m := range placeholderRegex.FindAllStringSubmatch(template, -1) {
+		if _, ok := placeholders[m[1]]; !ok {
+			return nil, fmt.Errorf("unknown placeholder {%s} in --name-template: expected one of index, title, author, number, section, start, end or source", m[1])
+		}
+	}
+	if strings.ContainsAny(placeholderRegex.ReplaceAllString(template, ""), `/\`) {
+		return nil, fmt.Errorf("--name-template '%s' must name a file without a directory", template)
+	}
+	return &nameTemplate{template: template, slug: slug}, nil
+}
+
+// fileName returns the name of the PDF for the values, ending in ".pdf": the filename set for
+// the article in the config, or else the template with its placeholders replaced. Separators
+// left at either end by empty values are dropped, and a name left empty is an error.
+func (t *nameTemplate) fileName(v nameValues) (string, error) {
+	if v.article.Filename != "" {
+		return withPDFExtension(v.article.Filename), nil
+	}
+	name := placeholderRegex.ReplaceAllStringFunc(t.template, func(placeholder string) string {
+		m := placeholderRegex.FindStringSubmatch(placeholder)
+		width, _ := strconv.Atoi(m[2])
+		if placeholders[m[1]] {
+			n := map[string]int{"index": v.index, "start": v.start, "end": v.end}[m[1]]
+			return fmt.Sprintf("%0*d", width, n)
+		}
+		text := map[string]string{
+			"title":   v.article.Title,
+			"author":  v.article.AuthorNames(),
+			"number":  v.article.Number,
+			"section": v.article.Section,
+			"source":  strings.TrimSuffix(filepath.Base(v.source), filepath.Ext(v.source)),
+		}[m[1]]
+		text = slugify(text, t.slug)
+		if runes := []rune(text); width > 0 && len(runes) > width {
+			text = string(runes[:width])
+			// Shorten to the last whole word rather than cut one
+			if i := strings.LastIndexAny(text, "_- "); i > 0 && !strings.ContainsRune("_- ", runes[width]) {
+				text = text[:i]
+			}
+			text = strings.TrimRight(text, "_-. ")
+		}
+		return text
+	})
+	if strings.HasSuffix(strings.ToLower(name), ".pdf") {
+		name = name[:len(name)-len(".pdf")]
+	}
+	name = strings.Trim(name, "_-. ")
+	if name == "" {
+		return "", fmt.Errorf("--name-template '%s' gives article '%s' an empty file name: set its filename in config.yaml", t.template, v.article.Title)
+	}
+	return name + ".pdf", nil
+}
+
+// withPDFExtension adds ".pdf" to a file name that does not end in it.
+func withPDFExtension(name string) string {
+	if !strings.HasSuffix(strings.ToLower(name), ".pdf") {
+		name += ".pdf"
+	}
+	return name
+}
+
+// slugify turns text into a part of a file name: words joined by underscores as before
+// templates were added, lower case words without accents joined by hyphens, or the text as it
+// is without the characters file systems do not allow.
+func slugify(text string, style string) string {
+	switch style {
+	case SlugHyphen:
+		var words []string
+		for _, word := range strings.FieldsFunc(norm.NFKD.String(text), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.Is(unicode.Mn, r) && !unicode.Is(unicode.Mc, r)
+		}) {
+			if word = utils.NormalizeText(word); word != "" {
+				words = append(words, word)
+			}
+		}
+		return strings.Join(words, "-")
+	case SlugKeep:
+		// Leading dots would hide the file or, as "..", leave the output directory
+		return strings.Trim(strings.Map(func(r rune) rune {
+			if unicode.IsControl(r) || strings.ContainsRune(`/\:*?"<>|`, r) {
+				return -1
+			}
+			return r
+		}, text), " .")
+	}
+	return utils.SanitizeFileName(text)
+}
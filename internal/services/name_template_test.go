@@ -0,0 +1,127 @@
+package services
+
+import (
+	"path/filepath"
+	"pdf-extractor/internal/models"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNameTemplateFileName(t *testing.T) {
+	article := models.Article{
+		Title:   "Rivers of the Plains: A Survey",
+		Authors: []models.Author{{Name: "A. Kumar"}, {Name: "B. Singh"}},
+		Number:  "1.2",
+		Section: "Research Articles",
+	}
+	values := nameValues{index: 3, article: article, start: 7, end: 12, source: "/journals/Hydrology Vol 3.pdf"}
+
+	tests := []struct {
+		name     string
+		template string
+		slug     string
+		values   nameValues
+		want     string
+	}{
+		{"default", DefaultNameTemplate, SlugUnderscore, values, "Rivers_of_the_Plains_A_Survey.pdf"},
+		{"numbers padded with zeros", "{index:02}-{start:3}-{end}", SlugUnderscore, values, "03-007-12.pdf"},
+		{"hyphen slugs", "{section}_{title}", SlugHyphen, values, "research-articles_rivers-of-the-plains-a-survey.pdf"},
+		{"kept text", "{number} {title}", SlugKeep, values, "1.2 Rivers of the Plains A Survey.pdf"},
+		{"authors and source", "{source}-{author}", SlugHyphen, values, "hydrology-vol-3-a-kumar-b-singh.pdf"},
+		{"text shortened to whole words", "{title:12}", SlugHyphen, values, "rivers-of.pdf"},
+		{"text shortened at a word end", "{title:9}", SlugHyphen, values, "rivers-of.pdf"},
+		{"a single long word is cut", "{title:4}", SlugHyphen, values, "rive.pdf"},
+		{"separators left by empty values", "{index}-{title}-{number}", SlugUnderscore, nameValues{index: 1, article: models.Article{Title: "Lakes"}}, "1-Lakes.pdf"},
+		{"extension in the template", "{title}.pdf", SlugUnderscore, values, "Rivers_of_the_Plains_A_Survey.pdf"},
+		{"accents", "{title}", SlugHyphen, nameValues{article: models.Article{Title: "Économie du Café"}}, "economie-du-cafe.pdf"},
+		{"Devanagari", "{title}", SlugHyphen, nameValues{article: models.Article{Title: "हिंदी भाषा का विकास"}}, "हिंदी-भाषा-का-विकास.pdf"},
+		{"characters file systems do not allow", "{title}", SlugKeep, nameValues{article: models.Article{Title: `../What is "water"?`}}, "What is water.pdf"},
+		{"filename from the config", "{index}-{title}", SlugHyphen, nameValues{index: 1, article: models.Article{Title: "Lakes", Filename: "lakes-survey"}}, "lakes-survey.pdf"},
+		{"filename with its extension", "{title}", SlugHyphen, nameValues{article: models.Article{Title: "Lakes", Filename: "Lakes.PDF"}}, "Lakes.PDF"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			names, err := newNameTemplate(tt.template, tt.slug)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := names.fileName(tt.values)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("fileName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNameTemplateEmptyName(t *testing.T) {
+	names, err := newNameTemplate("{number}-{section}", SlugHyphen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name, err := names.fileName(nameValues{article: models.Article{Title: "Lakes"}}); err == nil {
+		t.Errorf("fileName() = %q, want an error for an empty name", name)
+	}
+	names, err = newNameTemplate("{title}", SlugUnderscore)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name, err := names.fileName(nameValues{article: models.Article{Title: "?!"}}); err == nil {
+		t.Errorf("fileName() = %q, want an error for a title without letters or digits", name)
+	}
+}
+
+func TestNewNameTemplateErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		slug     string
+		want     string
+	}{
+		{"unknown slug style", "{title}", "camel", "unknown slug style 'camel'"},
+		{"empty template", " ", SlugUnderscore, "must not be empty"},
+		{"unknown placeholder", "{index}-{name}", SlugUnderscore, "unknown placeholder {name}"},
+		{"directory", "articles/{title}", SlugUnderscore, "without a directory"},
+		{"windows directory", `..\{title}`, SlugUnderscore, "without a directory"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newNameTemplate(tt.template, tt.slug)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("newNameTemplate(%q, %q) error = %v, want one containing %q", tt.template, tt.slug, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestUniqueOutputFiles(t *testing.T) {
+	dir := filepath.Join("out", "Research")
+	ranges := []articleRange{
+		{title: "Editorial", outputFile: filepath.Join(dir, "Editorial.pdf")},
+		{title: "Rivers", outputFile: filepath.Join(dir, "Rivers.pdf")},
+		{title: "Editorial", outputFile: filepath.Join(dir, "Editorial.pdf"), skip: true},
+		{title: "EDITORIAL", outputFile: filepath.Join(dir, "EDITORIAL.pdf")},
+		{title: "Editorial", outputFile: filepath.Join(dir, "Editorial.pdf")},
+		{title: "Editorial", outputFile: filepath.Join("out", "Reviews", "Editorial.pdf")},
+	}
+	uniqueOutputFiles(ranges)
+
+	var got []string
+	for _, r := range ranges {
+		got = append(got, r.outputFile)
+	}
+	want := []string{
+		filepath.Join(dir, "Editorial.pdf"),
+		filepath.Join(dir, "Rivers.pdf"),
+		filepath.Join(dir, "Editorial.pdf"),
+		filepath.Join(dir, "EDITORIAL-2.pdf"),
+		filepath.Join(dir, "Editorial-3.pdf"),
+		filepath.Join("out", "Reviews", "Editorial.pdf"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("uniqueOutputFiles() = %q, want %q", got, want)
+	}
+}
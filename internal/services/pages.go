@@ -0,0 +1,31 @@
+package services
+
+import (
+	"fmt"
+	"pdf-extractor/internal/extractor"
+	"pdf-extractor/internal/utils"
+)
+
+// loadPages returns the raw and normalized text of every page in the PDF,
+// reusing normalized text from the extractor when it already has it.
+func loadPages(ext extractor.TextExtractor, pdfPath string) ([]string, []string, error) {
+	// Extract the text of all pages in a single pass
+	pages, err := ext.ExtractPages(pdfPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to extract pages: %v", err)
+	}
+
+	if n, ok := ext.(extractor.NormalizedExtractor); ok {
+		normalized, err := n.ExtractNormalizedPages(pdfPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to extract pages: %v", err)
+		}
+		return pages, normalized, nil
+	}
+
+	normalized := make([]string, len(pages))
+	for i, content := range pages {
+		normalized[i] = utils.NormalizeText(content)
+	}
+	return pages, normalized, nil
+}
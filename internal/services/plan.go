@@ -0,0 +1,103 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"pdf-extractor/internal/utils"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	PlanTable = "table"
+	PlanJSON  = "json"
+
+	// snippetLength is how many characters of the matched text a plan shows
+	snippetLength = 60
+)
+
+// planStep is a change a command would make, as printed by --dry-run instead of making it.
+type planStep struct {
+	// Action is what would be done: extract, backup, delete pages or delete
+	Action string `json:"action"`
+	Title  string `json:"title,omitempty"`
+	From   int    `json:"from,omitempty"`
+	To     int    `json:"to,omitempty"`
+	// Match is the text on the first page that decided the pages
+	Match string `json:"match,omitempty"`
+	// Path is the file that would be written or removed
+	Path string `json:"path"`
+}
+
+// validatePlanFormat checks the format of a plan, where an empty format asks for no plan.
+func validatePlanFormat(format string) error {
+	if format != "" && format != PlanTable && format != PlanJSON {
+		return fmt.Errorf("unknown plan format '%s': expected '%s' or '%s'", format, PlanTable, PlanJSON)
+	}
+	return nil
+}
+
+// printPlan writes the steps to out as a table or as JSON.
+func printPlan(out io.Writer, steps []planStep, format string) error {
+	if format == PlanJSON {
+		data, err := json.MarshalIndent(steps, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode plan: %v", err)
+		}
+		fmt.Fprintln(out, string(data))
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ACTION\tPAGES\tTITLE\tMATCH\tPATH")
+	for _, s := range steps {
+		pages := "-"
+		switch {
+		case s.From > 0 && s.To > s.From:
+			pages = fmt.Sprintf("%d-%d", s.From, s.To)
+		case s.From > 0:
+			pages = fmt.Sprintf("%d", s.From)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", s.Action, pages, orDash(s.Title), orDash(s.Match), s.Path)
+	}
+	return w.Flush()
+}
+
+// orDash returns the text, or "-" in place of an empty cell.
+func orDash(text string) string {
+	if text == "" {
+		return "-"
+	}
+	return text
+}
+
+// backupStep is the backup of a file that CreateBackup would make.
+func backupStep(file string, backupPath string) planStep {
+	return planStep{Action: "backup", Path: filepath.Join(backupPath, strings.TrimSuffix(filepath.Base(file), ".pdf")) + string(filepath.Separator)}
+}
+
+// matchedSnippet returns the line of a page that comes closest to the title, or its first line
+// without a title, shortened for a plan.
+func matchedSnippet(content string, title string) string {
+	normalizedTitle := []rune(utils.NormalizeText(title))
+	best, bestScore := "", -1.0
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		score := 0.0
+		if len(normalizedTitle) > 0 {
+			score = prefixSimilarity(normalizedTitle, []rune(utils.NormalizeText(line)))
+		}
+		if score > bestScore {
+			best, bestScore = line, score
+		}
+	}
+	if runes := []rune(best); len(runes) > snippetLength {
+		best = string(runes[:snippetLength-1]) + "…"
+	}
+	return best
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"pdf-extractor/internal/cache"
+	"reflect"
+	"testing"
+)
+
+var planSteps = []planStep{
+	{Action: "backup", Path: "backup/journal/"},
+	{Action: "extract", Title: "Rivers of the Plains", From: 2, To: 3, Match: "Rivers of the Plains", Path: "out/Rivers_of_the_Plains.pdf"},
+	{Action: "extract", Title: "Lakes", From: 4, To: 4, Match: "Lakes", Path: "out/Lakes.pdf"},
+	{Action: "skip", Title: "Notes", From: 5, To: 6, Match: "Notes"},
+}
+
+func TestPrintPlanTable(t *testing.T) {
+	var out bytes.Buffer
+	if err := printPlan(&out, planSteps, PlanTable); err != nil {
+		t.Fatalf("printPlan() error = %v", err)
+	}
+	want := `ACTION   PAGES  TITLE                 MATCH                 PATH
+backup   -      -                     -                     backup/journal/
+extract  2-3    Rivers of the Plains  Rivers of the Plains  out/Rivers_of_the_Plains.pdf
+extract  4      Lakes                 Lakes                 out/Lakes.pdf
+skip     5-6    Notes                 Notes                 
+`
+	if got := out.String(); got != want {
+		t.Errorf("printPlan() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestPrintPlanJSON(t *testing.T) {
+	var out bytes.Buffer
+	if err := printPlan(&out, planSteps, PlanJSON); err != nil {
+		t.Fatalf("printPlan() error = %v", err)
+	}
+	var got []planStep
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("printPlan() printed invalid JSON: %v\n%s", err, out.String())
+	}
+	if !reflect.DeepEqual(got, planSteps) {
+		t.Errorf("printPlan() = %+v, want %+v", got, planSteps)
+	}
+	// Empty values are left out, but the path is always there
+	var raw []map[string]any
+	if err := json.Unmarshal(out.Bytes(), &raw); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := raw[0]["title"]; ok {
+		t.Errorf("backup step has a title: %v", raw[0])
+	}
+	if _, ok := raw[3]["path"]; !ok {
+		t.Errorf("skip step has no path: %v", raw[3])
+	}
+}
+
+func TestExtractPDFDryRun(t *testing.T) {
+	dir := t.TempDir()
+	pdfPath := filepath.Join(dir, "journal.pdf")
+	outputPath := filepath.Join(dir, "out")
+	cacheDir := filepath.Join(dir, "cache")
+	config := "articles:\n  - title: Editorial\n  - title: Lakes\n"
+	for path, content := range map[string]string{
+		pdfPath:                              "%PDF-1.4",
+		filepath.Join(dir, "config.yaml"):    config,
+		filepath.Join(outputPath, "old.pdf"): "kept",
+	} {
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A dry run reads from the cache without storing in it, as --dry-run sets it up
+	c := cache.New(cacheDir)
+	c.ReadOnly = true
+	ext := cache.NewExtractor(c, &stubExtractor{pages: journalPages})
+	pw := &recordingWriter{}
+	err := ExtractPDF(ext, pw, pdfPath, outputPath, dir, "", nil, DefaultHeaderThreshold, DefaultMatchThreshold, 2, DefaultNameTemplate, SlugUnderscore, PlanJSON)
+	if err != nil {
+		t.Fatalf("ExtractPDF() error = %v", err)
+	}
+
+	if len(pw.written) > 0 {
+		t.Errorf("a dry run wrote %q", pw.written)
+	}
+	entries, err := os.ReadDir(outputPath)
+	if err != nil || len(entries) != 1 || entries[0].Name() != "old.pdf" {
+		t.Errorf("a dry run changed the output directory: %v, %v", entries, err)
+	}
+	if _, err := os.Stat(cacheDir); !os.IsNotExist(err) {
+		t.Errorf("a dry run wrote to the cache: %v", err)
+	}
+}
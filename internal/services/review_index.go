@@ -0,0 +1,189 @@
+package services
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"pdf-extractor/internal/models"
+	"pdf-extractor/internal/toc"
+	"strconv"
+	"strings"
+)
+
+const reviewPrompt = "[a]ccept, edit [t]itle, a[u]thors or [p]age, [s]plit, [m]erge with next, [d]elete, [b]ack, [n]ext flagged, [q]uit and save, e[x]it without saving: "
+
+// reviewIndex walks through the entries, showing each next to the lines of the contents it was
+// read from, and lets the user accept, edit, split, merge or delete it. Prompts are written to
+// out and answers read from in, so that it works in any terminal. The end of the input accepts
+// the remaining entries.
+func reviewIndex(entries []toc.Entry, parser toc.TOCParser, fromOutline bool, in io.Reader, out io.Writer) ([]toc.Entry, error) {
+	reader := bufio.NewReader(in)
+	ask := func(prompt string) (string, bool) {
+		fmt.Fprint(out, prompt)
+		answer, err := reader.ReadString('\n')
+		if err != nil && answer == "" {
+			fmt.Fprintln(out)
+			return "", false
+		}
+		return strings.TrimSpace(answer), true
+	}
+
+	fmt.Fprintf(out, "Reviewing %d entries. Press Enter to accept an entry as it is.\n", len(entries))
+	for i := 0; i < len(entries); {
+		reviews := reviewEntries(entries, fromOutline)
+		printEntry(out, i, entries, reviews[i])
+		answer, ok := ask(reviewPrompt)
+		if !ok {
+			return entries, nil
+		}
+
+		e := &entries[i]
+		switch strings.ToLower(answer) {
+		case "", "a":
+			i++
+		case "t":
+			if title, ok := ask(fmt.Sprintf("Title [%s]: ", e.Title)); ok && title != "" {
+				e.Title, e.TitleLines = title, 1
+			}
+		case "u":
+			if authors, ok := ask(fmt.Sprintf("Authors, or - for none [%s]: ", e.AuthorNames())); ok && authors != "" {
+				e.Authors = nil
+				if authors != "-" {
+					e.Authors = models.ParseAuthors(authors)
+				}
+			}
+		case "p":
+			if answer, ok := ask(fmt.Sprintf("Printed page, or 0 for none [%d]: ", e.Page)); ok && answer != "" {
+				page, err := strconv.Atoi(answer)
+				if err != nil || page < 0 {
+					fmt.Fprintf(out, "'%s' is not a page number\n", answer)
+					continue
+				}
+				e.Page = page
+			}
+		case "s":
+			if len(e.Lines) < 2 {
+				fmt.Fprintln(out, "Only entries read from two or more lines of the contents can be split")
+				continue
+			}
+			answer, ok := ask(fmt.Sprintf("Start the second entry at line (2-%d): ", len(e.Lines)))
+			line, err := strconv.Atoi(answer)
+			if !ok || err != nil || line < 2 || line > len(e.Lines) {
+				fmt.Fprintln(out, "Not split")
+				continue
+			}
+			first := reparseEntry(parser, e.Lines[:line-1:line-1], *e)
+			second := reparseEntry(parser, e.Lines[line-1:], toc.Entry{Article: models.Article{Level: e.Level, Section: e.Section}})
+			entries = append(entries[:i], append([]toc.Entry{first, second}, entries[i+1:]...)...)
+		case "m":
+			if i+1 == len(entries) {
+				fmt.Fprintln(out, "The last entry has no next entry to merge with")
+				continue
+			}
+			entries[i] = mergeEntries(parser, *e, entries[i+1])
+			entries = append(entries[:i+1], entries[i+2:]...)
+		case "d":
+			entries = append(entries[:i], entries[i+1:]...)
+		case "b":
+			i = max(i-1, 0)
+		case "n":
+			next := i + 1
+			for next < len(entries) && reviews[next].Confidence >= lowConfidence {
+				next++
+			}
+			if next == len(entries) {
+				fmt.Fprintln(out, "No more flagged entries")
+				continue
+			}
+			i = next
+		case "q":
+			return entries, nil
+		case "x":
+			return nil, fmt.Errorf("review aborted")
+		default:
+			fmt.Fprintf(out, "Unknown answer '%s'\n", answer)
+		}
+	}
+	return entries, nil
+}
+
+// printEntry shows an entry along with the review of it and the lines it was read from.
+func printEntry(out io.Writer, i int, entries []toc.Entry, review entryReview) {
+	e := entries[i]
+	fmt.Fprintln(out)
+	title := e.Title
+	if e.Number != "" && e.Number != e.Title {
+		title = e.Number + " " + title
+	}
+	fmt.Fprintf(out, "[%d/%d] %s\n", i+1, len(entries), title)
+	if e.Section != "" {
+		fmt.Fprintf(out, "    section:    %s\n", e.Section)
+	}
+	if len(e.Authors) > 0 {
+		fmt.Fprintf(out, "    authors:    %s\n", e.AuthorNames())
+	}
+	if e.Page > 0 {
+		fmt.Fprintf(out, "    page:       %d\n", e.Page)
+	}
+	if e.PhysicalPage > 0 {
+		fmt.Fprintf(out, "    PDF page:   %d\n", e.PhysicalPage)
+	}
+	fmt.Fprintf(out, "    confidence: %.2f\n", review.Confidence)
+	for _, warning := range review.Warnings {
+		fmt.Fprintf(out, "    warning:    %s\n", warning)
+	}
+	if len(e.Lines) > 0 {
+		fmt.Fprintln(out, "  From the contents:")
+		for n, line := range e.Lines {
+			fmt.Fprintf(out, "    %2d | %s\n", n+1, line)
+		}
+	}
+}
+
+// reparseEntry reads an entry from some lines of the contents again, keeping the number of
+// like when the lines no longer carry it, and its level and section. Lines the parser would
+// not read as one entry, such as a title without its number, are read as one all the same.
+func reparseEntry(parser toc.TOCParser, lines []string, like toc.Entry) toc.Entry {
+	e, ok := parseOne(parser, lines)
+	if !ok {
+		e = parser.ParseEntry(lines)
+	}
+	e.Lines = lines
+	if e.Number == "" {
+		e.Number = like.Number
+	}
+	e.Level, e.Section = like.Level, like.Section
+	return e
+}
+
+// parseOne reads lines as a single entry, failing when the parser skips some of them.
+func parseOne(parser toc.TOCParser, lines []string) (toc.Entry, bool) {
+	parsed, err := parser.Parse(strings.Join(lines, "\n"))
+	if err != nil || len(parsed) != 1 || len(parsed[0].Lines) != len(lines) {
+		return toc.Entry{}, false
+	}
+	return parsed[0], true
+}
+
+// mergeEntries joins an entry with the next, as when a title wrapped onto a line the parser
+// took for a new entry.
+func mergeEntries(parser toc.TOCParser, e, next toc.Entry) toc.Entry {
+	if len(e.Lines) > 0 && len(next.Lines) > 0 {
+		if merged, ok := parseOne(parser, append(append([]string{}, e.Lines...), next.Lines...)); ok {
+			merged.Number, merged.Level, merged.Section = e.Number, e.Level, e.Section
+			return merged
+		}
+	}
+	// The number of the next entry was most likely read from the title
+	e.Title = strings.Join(strings.Fields(e.Title+" "+next.Number+" "+next.Title), " ")
+	e.TitleLines += next.TitleLines
+	e.Authors = append(e.Authors, next.Authors...)
+	e.Lines = append(e.Lines, next.Lines...)
+	if e.Page == 0 {
+		e.Page = next.Page
+	}
+	if e.PhysicalPage == 0 {
+		e.PhysicalPage = next.PhysicalPage
+	}
+	return e
+}
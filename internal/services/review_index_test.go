@@ -0,0 +1,174 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"pdf-extractor/internal/toc"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// reviewContents lists three articles, the first two of which the parser reads as one, as
+// when a number is missing from the contents, and one with a title that wrapped onto a line
+// read as a new entry.
+const reviewContents = `1. Rivers of the Plains 3
+A. Kumar
+Lakes 9
+B. Singh
+2. Water in
+2050. A Forecast 15
+C. Rao
+3. Seas 21
+D. Mehta`
+
+// entryLines shows the number, title, authors and page of each entry.
+func entryLines(entries []toc.Entry) []string {
+	var lines []string
+	for _, e := range entries {
+		lines = append(lines, fmt.Sprintf("%s|%s|%s|%d", e.Number, e.Title, e.AuthorNames(), e.Page))
+	}
+	return lines
+}
+
+func TestReviewIndex(t *testing.T) {
+	parser, err := toc.New(toc.LayoutNumbered, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		answers string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "accept",
+			answers: "\na\n\n\n",
+			want: []string{
+				"1|Rivers of the Plains A. Kumar Lakes|B. Singh|3",
+				"2|Water in||0",
+				"2050|A Forecast|C. Rao|15",
+				"3|Seas|D. Mehta|21",
+			},
+		},
+		{
+			name:    "end of the answers accepts the rest",
+			answers: "",
+			want: []string{
+				"1|Rivers of the Plains A. Kumar Lakes|B. Singh|3",
+				"2|Water in||0",
+				"2050|A Forecast|C. Rao|15",
+				"3|Seas|D. Mehta|21",
+			},
+		},
+		{
+			name:    "edit",
+			answers: "t\nRivers of the Plains\nu\nA. Kumar and B. Singh\np\n4\n",
+			want: []string{
+				"1|Rivers of the Plains|A. Kumar, B. Singh|4",
+				"2|Water in||0",
+				"2050|A Forecast|C. Rao|15",
+				"3|Seas|D. Mehta|21",
+			},
+		},
+		{
+			name:    "no authors and a page that is not a number",
+			answers: "u\n-\np\nfour\n",
+			want: []string{
+				"1|Rivers of the Plains A. Kumar Lakes||3",
+				"2|Water in||0",
+				"2050|A Forecast|C. Rao|15",
+				"3|Seas|D. Mehta|21",
+			},
+		},
+		{
+			name:    "split",
+			answers: "s\n3\n",
+			want: []string{
+				"1|Rivers of the Plains|A. Kumar|3",
+				"|Lakes|B. Singh|9",
+				"2|Water in||0",
+				"2050|A Forecast|C. Rao|15",
+				"3|Seas|D. Mehta|21",
+			},
+		},
+		{
+			name:    "split at a line out of range",
+			answers: "s\n5\n",
+			want: []string{
+				"1|Rivers of the Plains A. Kumar Lakes|B. Singh|3",
+				"2|Water in||0",
+				"2050|A Forecast|C. Rao|15",
+				"3|Seas|D. Mehta|21",
+			},
+		},
+		{
+			name:    "merge",
+			answers: "\nm\n",
+			want: []string{
+				"1|Rivers of the Plains A. Kumar Lakes|B. Singh|3",
+				"2|Water in 2050 A Forecast|C. Rao|15",
+				"3|Seas|D. Mehta|21",
+			},
+		},
+		{
+			name:    "merge the last entry",
+			answers: "\n\n\nm\n",
+			want: []string{
+				"1|Rivers of the Plains A. Kumar Lakes|B. Singh|3",
+				"2|Water in||0",
+				"2050|A Forecast|C. Rao|15",
+				"3|Seas|D. Mehta|21",
+			},
+		},
+		{
+			name:    "delete",
+			answers: "\nd\n",
+			want: []string{
+				"1|Rivers of the Plains A. Kumar Lakes|B. Singh|3",
+				"2050|A Forecast|C. Rao|15",
+				"3|Seas|D. Mehta|21",
+			},
+		},
+		{
+			name:    "back",
+			answers: "\n\nb\nd\n",
+			want: []string{
+				"1|Rivers of the Plains A. Kumar Lakes|B. Singh|3",
+				"2050|A Forecast|C. Rao|15",
+				"3|Seas|D. Mehta|21",
+			},
+		},
+		{
+			name:    "quit keeps the changes",
+			answers: "t\nRivers of the Plains\nq\n",
+			want: []string{
+				"1|Rivers of the Plains|B. Singh|3",
+				"2|Water in||0",
+				"2050|A Forecast|C. Rao|15",
+				"3|Seas|D. Mehta|21",
+			},
+		},
+		{
+			name:    "exit without saving",
+			answers: "t\nRivers of the Plains\nx\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := parser.Parse(reviewContents)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := reviewIndex(entries, parser, false, strings.NewReader(tt.answers), io.Discard)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("reviewIndex() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(entryLines(got), tt.want) {
+				t.Errorf("reviewIndex() = %q, want %q", entryLines(got), tt.want)
+			}
+		})
+	}
+}
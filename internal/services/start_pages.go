@@ -0,0 +1,197 @@
+package services
+
+import (
+	"math"
+	"pdf-extractor/internal/extractor"
+	"pdf-extractor/internal/models"
+	"pdf-extractor/internal/toc"
+	"pdf-extractor/internal/utils"
+	"slices"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// headingMatch is the score of a page with the title as a heading, preferred over the same words in body text
+	headingMatch = 1.5
+	// textMatch is the score of a page starting with the title
+	textMatch = 1.0
+	// contentsTitles is how many titles a page must list to be taken for the contents
+	contentsTitles = 3
+	// contentsTitleWords is how many words a title needs to be looked for on the contents, so
+	// that titles such as "Editorial" found in any text do not count
+	contentsTitleWords = 3
+)
+
+// candidate is a page an article may start on, scored by how well it matches the title.
+type candidate struct {
+	page  int
+	score float64
+	// fuzzy is set when the title was only matched approximately
+	fuzzy bool
+}
+
+// articleCandidates returns the pages an article may start on, in order. The title may be
+// printed with the number of the entry, as in "Chapter 3 The Dawn of Time". When the title is
+// not found as printed, the pages whose top lines come close enough to it are returned.
+func articleCandidates(layout []extractor.Page, pageContents []string, tops [][]string, article models.Article, matchThreshold float64, skip map[int]bool) []candidate {
+	titles := []string{article.Title}
+	if article.Number != "" && article.Number != article.Title {
+		titles = append(titles, article.Number+" "+article.Title)
+	}
+
+	byPage := map[int]candidate{}
+	add := func(c candidate) {
+		if old, ok := byPage[c.page]; !skip[c.page] && (!ok || c.score > old.score) {
+			byPage[c.page] = c
+		}
+	}
+	for _, title := range titles {
+		for _, page := range titleHeadingPages(layout, title) {
+			add(candidate{page: page, score: headingMatch})
+		}
+		for page, normalizedContent := range pageContents {
+			if matchArticleTitleByLength(normalizedContent, title) {
+				add(candidate{page: page + 1, score: textMatch})
+			}
+		}
+	}
+	if len(byPage) == 0 && matchThreshold < 1 {
+		for _, c := range fuzzyCandidates(tops, titles, matchThreshold, skip) {
+			add(c)
+		}
+	}
+
+	candidates := make([]candidate, 0, len(byPage))
+	for _, c := range byPage {
+		candidates = append(candidates, c)
+	}
+	slices.SortFunc(candidates, func(a, b candidate) int { return a.page - b.page })
+	return candidates
+}
+
+// contentsPages returns the pages that are headed by one of the contents keywords or list
+// several of the titles, which no article starts on even though its title is printed there.
+func contentsPages(pageContents []string, tops [][]string, articles []models.Article, keywords []string) map[int]bool {
+	var titles []string
+	for _, article := range articles {
+		if len(strings.Fields(article.Title)) >= contentsTitleWords {
+			titles = append(titles, utils.NormalizeText(article.Title))
+		}
+	}
+	need := min(contentsTitles, len(titles))
+
+	pages := map[int]bool{}
+	for i, content := range pageContents {
+		listed := 0
+		for _, title := range titles {
+			if strings.Contains(content, title) {
+				listed++
+			}
+		}
+		headed := slices.ContainsFunc(tops[i], func(line string) bool {
+			return toc.IsContentsHeading(line, keywords)
+		})
+		if headed || (need >= 2 && listed >= need) {
+			pages[i+1] = true
+		}
+	}
+	return pages
+}
+
+// resolveStartPages picks a start page for every article from its candidates, or 0, so that
+// the start pages never decrease in the order of the articles and the candidates picked score
+// the most in total. Articles may share a page. Of equally good choices the earliest pages win.
+func resolveStartPages(candidates [][]candidate, totalPages int) []int {
+	// best[p] is the highest score of the articles so far with none starting after page p,
+	// and choice[i][p] the page article i starts on in it, or 0 when it is left out
+	best := make([]float64, totalPages+1)
+	choice := make([][]int, len(candidates))
+	for i, cs := range candidates {
+		scores := make([]float64, totalPages+1)
+		for p := range scores {
+			scores[p] = math.Inf(-1)
+		}
+		for _, c := range cs {
+			if c.page >= 1 && c.page <= totalPages {
+				scores[c.page] = best[c.page] + c.score
+			}
+		}
+
+		choice[i] = make([]int, totalPages+1)
+		next := make([]float64, totalPages+1)
+		top, topPage := math.Inf(-1), 0
+		for p := 0; p <= totalPages; p++ {
+			if scores[p] > top {
+				top, topPage = scores[p], p
+			}
+			next[p] = best[p]
+			if top > best[p] {
+				next[p], choice[i][p] = top, topPage
+			}
+		}
+		best = next
+	}
+
+	pages := make([]int, len(candidates))
+	last := totalPages
+	for i := len(candidates) - 1; i >= 0; i-- {
+		if page := choice[i][last]; page > 0 {
+			pages[i], last = page, page
+		}
+	}
+	return pages
+}
+
+// findStartPages returns the page every article starts on, or 0, resolving titles printed on
+// several pages jointly so that articles start in their order and not on the contents.
+// Articles with a start page set or read from the outline keep that page.
+func findStartPages(layout []extractor.Page, pageContents []string, tops [][]string, articles []models.Article, contentsKeywords []string, matchThreshold float64) []int {
+	totalPages := len(pageContents)
+	skip := contentsPages(pageContents, tops, articles, contentsKeywords)
+	if len(skip) > 0 {
+		pages := make([]int, 0, len(skip))
+		for page := range skip {
+			pages = append(pages, page)
+		}
+		slices.Sort(pages)
+		logrus.Infof("Skipping pages %v when looking for titles, as they list the contents", pages)
+	}
+
+	// A page set by hand or from the outline outweighs any number of titles found
+	anchor := headingMatch * float64(len(articles)+1)
+	candidates := make([][]candidate, len(articles))
+	for i, article := range articles {
+		if page := article.FixedStart(); page > 0 {
+			candidates[i] = []candidate{{page: page, score: anchor}}
+			continue
+		}
+		candidates[i] = articleCandidates(layout, pageContents, tops, article, matchThreshold, skip)
+	}
+
+	pages := resolveStartPages(candidates, totalPages)
+	for i, article := range articles {
+		if article.FixedStart() > 0 {
+			continue
+		}
+		for _, c := range candidates[i] {
+			if c.page == pages[i] && c.fuzzy {
+				logrus.Infof("Matched article '%s' to page %d with similarity %.2f", article.Title, c.page, c.score)
+			}
+		}
+		if pages[i] == 0 && len(candidates[i]) > 0 {
+			logrus.Warnf("Article '%s' is only found on pages out of order with the other articles (%v)", article.Title, candidatePages(candidates[i]))
+		}
+	}
+	return pages
+}
+
+// candidatePages returns the pages of the candidates.
+func candidatePages(candidates []candidate) []int {
+	pages := make([]int, len(candidates))
+	for i, c := range candidates {
+		pages[i] = c.page
+	}
+	return pages
+}
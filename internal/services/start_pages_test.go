@@ -0,0 +1,226 @@
+package services
+
+import (
+	"pdf-extractor/internal/models"
+	"pdf-extractor/internal/toc"
+	"pdf-extractor/internal/utils"
+	"reflect"
+	"testing"
+)
+
+func TestResolveStartPages(t *testing.T) {
+	tests := []struct {
+		name       string
+		candidates [][]candidate
+		totalPages int
+		want       []int
+	}{
+		{
+			name: "one candidate each",
+			candidates: [][]candidate{
+				{{page: 3, score: textMatch}},
+				{{page: 8, score: textMatch}},
+			},
+			totalPages: 10,
+			want:       []int{3, 8},
+		},
+		{
+			name: "duplicate titles take pages in order",
+			candidates: [][]candidate{
+				{{page: 2, score: headingMatch}, {page: 9, score: headingMatch}},
+				{{page: 5, score: textMatch}},
+				{{page: 2, score: headingMatch}, {page: 9, score: headingMatch}},
+			},
+			totalPages: 12,
+			want:       []int{2, 5, 9},
+		},
+		{
+			name: "a title also printed in an earlier article",
+			candidates: [][]candidate{
+				{{page: 3, score: textMatch}},
+				{{page: 4, score: textMatch}, {page: 10, score: textMatch}},
+				{{page: 7, score: textMatch}},
+			},
+			totalPages: 12,
+			want:       []int{3, 4, 7},
+		},
+		{
+			name: "an article out of order is left out",
+			candidates: [][]candidate{
+				{{page: 3, score: textMatch}},
+				{{page: 1, score: textMatch}},
+				{{page: 7, score: textMatch}},
+			},
+			totalPages: 10,
+			want:       []int{3, 0, 7},
+		},
+		{
+			name: "headings outweigh text",
+			candidates: [][]candidate{
+				{{page: 2, score: textMatch}, {page: 6, score: headingMatch}},
+				{{page: 4, score: textMatch}},
+			},
+			totalPages: 10,
+			want:       []int{2, 4},
+		},
+		{
+			name: "ties go to the earliest pages",
+			candidates: [][]candidate{
+				{{page: 2, score: textMatch}, {page: 6, score: textMatch}},
+				{{page: 7, score: textMatch}, {page: 9, score: textMatch}},
+			},
+			totalPages: 10,
+			want:       []int{2, 7},
+		},
+		{
+			name: "articles sharing a page",
+			candidates: [][]candidate{
+				{{page: 4, score: textMatch}},
+				{{page: 4, score: textMatch}},
+			},
+			totalPages: 10,
+			want:       []int{4, 4},
+		},
+		{
+			name: "pages beyond the document are ignored",
+			candidates: [][]candidate{
+				{{page: 12, score: textMatch}},
+				{},
+				{{page: 0, score: textMatch}, {page: 5, score: textMatch}},
+			},
+			totalPages: 10,
+			want:       []int{0, 0, 5},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolveStartPages(tt.candidates, tt.totalPages); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resolveStartPages() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// normalizedPages returns the normalized content and top lines of pages, as extract reads them.
+func normalizedPages(pages []string) ([]string, [][]string) {
+	contents := make([]string, len(pages))
+	for i, content := range pages {
+		contents[i] = utils.NormalizeText(content)
+	}
+	return contents, pageTops(pages)
+}
+
+func TestContentsPages(t *testing.T) {
+	articles := []models.Article{
+		{Title: "Rivers of the Plains"},
+		{Title: "Groundwater Recharge in Dry Regions"},
+		{Title: "Lakes and Their Shores"},
+		{Title: "Editorial"},
+	}
+	tests := []struct {
+		name     string
+		pages    []string
+		keywords []string
+		want     map[int]bool
+	}{
+		{
+			name: "headed by a keyword",
+			pages: []string{
+				"Journal of Hydrology",
+				"Contents\nEditorial 1",
+				"Editorial\nThe rivers",
+			},
+			keywords: toc.DefaultContentsKeywords,
+			want:     map[int]bool{2: true},
+		},
+		{
+			name: "headed by a configured keyword",
+			pages: []string{
+				"In this volume\nEditorial 1",
+				"Contents\nof the survey",
+			},
+			keywords: []string{"In this volume"},
+			want:     map[int]bool{1: true},
+		},
+		{
+			name: "listing the titles without a heading",
+			pages: []string{
+				"Rivers of the Plains 3\nGroundwater Recharge in Dry Regions 9\nLakes and Their Shores 15",
+				"Rivers of the Plains\nThe rivers",
+			},
+			keywords: toc.DefaultContentsKeywords,
+			want:     map[int]bool{1: true},
+		},
+		{
+			name: "short titles do not count",
+			pages: []string{
+				"Rivers of the Plains\nEditorial\nLakes and Their Shores",
+			},
+			keywords: toc.DefaultContentsKeywords,
+			want:     map[int]bool{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contents, tops := normalizedPages(tt.pages)
+			if got := contentsPages(contents, tops, articles, tt.keywords); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("contentsPages() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindStartPages(t *testing.T) {
+	pages := []string{
+		"Contents\nIntroduction 1\nRivers of the Plains 3\nIntroduction 6\nLakes 8",
+		"Introduction\nThis volume",
+		"Rivers of the Plains\nA. Kumar",
+		"The rivers",
+		"Introduction\nThe rivers",
+		"Introduction\nThis part",
+		"Lakes\nB. Singh",
+		"Lakes are",
+		"Groundwater Recharge in Dry Regons\nC. Rao",
+	}
+	articles := []models.Article{
+		{Title: "Introduction"},
+		{Title: "Rivers of the Plains"},
+		{Title: "Introduction"},
+		{Title: "Lakes", Start: 7},
+		{Title: "Groundwater Recharge in Dry Regions"},
+		{Title: "Missing Article"},
+	}
+	contents, tops := normalizedPages(pages)
+
+	got := findStartPages(nil, contents, tops, articles, toc.DefaultContentsKeywords, DefaultMatchThreshold)
+	want := []int{2, 3, 5, 7, 9, 0}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("findStartPages() = %v, want %v", got, want)
+	}
+
+	// Titles that are not found as printed are not matched approximately at a threshold of 1
+	got = findStartPages(nil, contents, tops, articles, toc.DefaultContentsKeywords, 1)
+	if got[4] != 0 {
+		t.Errorf("findStartPages() at threshold 1 matched the misspelled title to page %d", got[4])
+	}
+}
+
+func TestFindStartPagesAnchor(t *testing.T) {
+	pages := []string{
+		"Rivers of the Plains\nA. Kumar",
+		"Lakes\nB. Singh",
+		"The lakes",
+		"Lakes of the North\nC. Rao",
+	}
+	articles := []models.Article{
+		{Title: "Rivers of the Plains"},
+		{Title: "Lakes", Start: 4},
+	}
+	contents, tops := normalizedPages(pages)
+
+	// The start set by hand wins over the title printed on page 2
+	got := findStartPages(nil, contents, tops, articles, toc.DefaultContentsKeywords, DefaultMatchThreshold)
+	if want := []int{1, 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("findStartPages() = %v, want %v", got, want)
+	}
+}
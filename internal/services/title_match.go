@@ -0,0 +1,87 @@
+package services
+
+import (
+	"pdf-extractor/internal/utils"
+	"strings"
+)
+
+const (
+	// DefaultMatchThreshold is the similarity a page must reach to be matched to a title not found as printed
+	DefaultMatchThreshold = 0.85
+	// matchLines is how many lines at the top of each page a title is compared with
+	matchLines = 8
+)
+
+// pageTops returns the normalized text of the first lines of every page that are not empty.
+func pageTops(pages []string) [][]string {
+	tops := make([][]string, len(pages))
+	for i, content := range pages {
+		for _, line := range strings.Split(content, "\n") {
+			if text := utils.NormalizeText(line); text != "" {
+				tops[i] = append(tops[i], text)
+				if len(tops[i]) == matchLines {
+					break
+				}
+			}
+		}
+	}
+	return tops
+}
+
+// fuzzyCandidates returns the pages whose top lines come close enough to one of the titles,
+// scored by their similarity.
+func fuzzyCandidates(tops [][]string, titles []string, threshold float64, skip map[int]bool) []candidate {
+	var candidates []candidate
+	for page, lines := range tops {
+		if skip[page+1] {
+			continue
+		}
+		best := 0.0
+		for _, title := range titles {
+			normalizedTitle := []rune(utils.NormalizeText(title))
+			if len(normalizedTitle) == 0 {
+				continue
+			}
+			// The title may start on any of the lines and wrap onto the next ones
+			for j := range lines {
+				best = max(best, prefixSimilarity(normalizedTitle, []rune(strings.Join(lines[j:], ""))))
+			}
+		}
+		if best >= threshold {
+			candidates = append(candidates, candidate{page: page + 1, score: best, fuzzy: true})
+		}
+	}
+	return candidates
+}
+
+// prefixSimilarity returns how closely the start of text matches title, between 0 and 1: one
+// minus the edit distance between title and the closest prefix of text, relative to the length
+// of the title. Text running on after the title does not lower the similarity.
+func prefixSimilarity(title, text []rune) float64 {
+	// Prefixes much longer than the title cannot come closer than shorter ones
+	text = text[:min(len(text), len(title)*3/2+1)]
+
+	// row[k] is the edit distance between the title so far and the first k runes of text
+	row := make([]int, len(text)+1)
+	for k := range row {
+		row[k] = k
+	}
+	for i := 1; i <= len(title); i++ {
+		diagonal := row[0]
+		row[0] = i
+		for k := 1; k <= len(text); k++ {
+			cost := 1
+			if title[i-1] == text[k-1] {
+				cost = 0
+			}
+			above := row[k]
+			row[k] = min(row[k]+1, row[k-1]+1, diagonal+cost)
+			diagonal = above
+		}
+	}
+	distance := len(title)
+	for _, d := range row {
+		distance = min(distance, d)
+	}
+	return 1 - float64(distance)/float64(len(title))
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestPrefixSimilarity(t *testing.T) {
+	tests := []struct {
+		name  string
+		title string
+		text  string
+		want  float64
+	}{
+		{"exact", "rivers", "rivers", 1},
+		{"text running on", "rivers", "riversoftheplains", 1},
+		{"one substitution", "rivers", "rovers", 5.0 / 6},
+		{"one missing rune", "rivers", "rivrs", 5.0 / 6},
+		{"one extra rune", "rivers", "riverrs", 5.0 / 6},
+		{"OCR noise", "groundwaterrecharge", "gr0undwaterrechargeindryregions", 18.0 / 19},
+		{"Devanagari", "नदियाँ", "नदियां", 5.0 / 6},
+		{"unrelated", "rivers", "lakes", 2.0 / 6},
+		{"title later in the text", "rivers", "lakesandrivers", 2.0 / 6},
+		{"empty text", "rivers", "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := prefixSimilarity([]rune(tt.title), []rune(tt.text))
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("prefixSimilarity(%q, %q) = %v, want %v", tt.title, tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPageTops(t *testing.T) {
+	pages := []string{
+		"\n  Rivers of the Plains\n\n- 3 -\n",
+		"1\n2\n3\n4\n5\n6\n7\n8\n9\n10",
+		"",
+	}
+	want := [][]string{
+		{"riversoftheplains", "3"},
+		{"1", "2", "3", "4", "5", "6", "7", "8"},
+		nil,
+	}
+	if got := pageTops(pages); !reflect.DeepEqual(got, want) {
+		t.Errorf("pageTops() = %q, want %q", got, want)
+	}
+}
+
+func TestFuzzyCandidates(t *testing.T) {
+	tops := pageTops([]string{
+		"Journal of Hydrology\nRivers of the\nPlalns\nA. Kumar",
+		"Rivers of the Plains\nA. Kumar",
+		"Lakes\nB. Singh",
+	})
+	got := fuzzyCandidates(tops, []string{"Rivers of the Plains"}, DefaultMatchThreshold, map[int]bool{2: true})
+	if len(got) != 1 || got[0].page != 1 || !got[0].fuzzy || got[0].score < DefaultMatchThreshold || got[0].score == 1 {
+		t.Errorf("fuzzyCandidates() = %+v, want page 1 only", got)
+	}
+}
@@ -0,0 +1,159 @@
+package toc
+
+import (
+	"pdf-extractor/internal/utils"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	// numberingRegex matches the number an entry starts with: a keyword followed by a number
+	// ("Chapter 3", "Part II", "Appendix A"), decimal numbering ("1.2", "1.2.3."), an arabic
+	// number ("1.") or a roman numeral ("IV.")
+	numberingRegex = regexp.MustCompile(`^(?:(?i:(chapter|part|section|book|unit|appendix))\s+(\p{Nd}+|[IVXLCDM]+|\p{Lu})\b|(\p{Nd}+(?:\.\p{Nd}+)+)\.?|(\p{Nd}+)\.|([IVXLCDM]+)\.)[\s:.–—-]*`)
+	keywordRegex   = regexp.MustCompile(`^(?i)(chapter|part|section|book|unit|appendix)\s`)
+	decimalRegex   = regexp.MustCompile(`^\p{Nd}+(?:\.\p{Nd}+)*$`)
+)
+
+// entryNumber is the number an entry of the contents starts with.
+type entryNumber struct {
+	// label is the number as printed, without the punctuation following it
+	label string
+	// style identifies the kind of numbering, so that entries numbered alike share a level
+	style string
+	// roman is the value of a bare roman numeral, which is only accepted in sequence
+	roman int
+}
+
+// matchNumbering returns the number a line starts with and the rest of the line.
+func matchNumbering(line string) (entryNumber, string, bool) {
+	m := numberingRegex.FindStringSubmatch(line)
+	if m == nil {
+		return entryNumber{}, line, false
+	}
+	rest := strings.TrimSpace(line[len(m[0]):])
+	switch {
+	case m[1] != "":
+		return entryNumber{label: m[1] + " " + m[2], style: strings.ToLower(m[1])}, rest, true
+	case m[3] != "":
+		return entryNumber{label: m[3], style: decimalStyle(m[3])}, rest, true
+	case m[4] != "":
+		return entryNumber{label: m[4], style: decimalStyle(m[4])}, rest, true
+	}
+	return entryNumber{label: m[5], style: "roman", roman: parseRoman(m[5])}, rest, true
+}
+
+// decimalStyle tells decimal numbers apart by how many levels they have, so "1.2" nests under "1".
+func decimalStyle(label string) string {
+	return "decimal" + strings.Repeat(".", strings.Count(label, "."))
+}
+
+// numberStyle returns the style of a number captured by a custom entry_start pattern.
+func numberStyle(label string) string {
+	label = strings.TrimRight(strings.TrimSpace(label), ".:")
+	if m := keywordRegex.FindStringSubmatch(label); m != nil {
+		return strings.ToLower(m[1])
+	}
+	if decimalRegex.MatchString(label) {
+		return decimalStyle(label)
+	}
+	if parseRoman(label) > 0 {
+		return "roman"
+	}
+	return "other"
+}
+
+// parseRoman returns the value of a roman numeral, or 0 when it is not one.
+func parseRoman(s string) int {
+	values := map[byte]int{'I': 1, 'V': 5, 'X': 10, 'L': 50, 'C': 100, 'D': 500, 'M': 1000}
+	s = strings.ToUpper(s)
+	total := 0
+	for i := 0; i < len(s); i++ {
+		v, ok := values[s[i]]
+		if !ok {
+			return 0
+		}
+		if i+1 < len(s) && values[s[i+1]] > v {
+			total -= v
+		} else {
+			total += v
+		}
+	}
+	return total
+}
+
+// acceptedRomans returns the lines starting with a roman numeral that continues the sequence
+// I, II, III... Initials such as "C. Rao" look like roman numerals too, so the first numeral
+// is only accepted when "II" follows it.
+func acceptedRomans(lines []string) map[int]bool {
+	type candidate struct{ line, value int }
+	var candidates []candidate
+	for i, line := range lines {
+		if n, _, ok := matchNumbering(line); ok && n.roman > 0 {
+			candidates = append(candidates, candidate{i, n.roman})
+		}
+	}
+	accepted := map[int]bool{}
+	last := 0
+	for k, c := range candidates {
+		if c.value != last+1 {
+			continue
+		}
+		if last == 0 && !slices.ContainsFunc(candidates[k+1:], func(next candidate) bool { return next.value == 2 }) {
+			continue
+		}
+		accepted[c.line] = true
+		last = c.value
+	}
+	return accepted
+}
+
+// levels assigns a depth to every style of numbering in the order they nest: a style seen
+// for the first time is one level deeper than the entry before it, and a style seen before
+// returns to its level, as when "Part II" follows the chapters of part I.
+type levels struct {
+	styles []string
+}
+
+func (l *levels) level(style string) int {
+	for i, s := range l.styles {
+		if s == style {
+			l.styles = l.styles[:i+1]
+			return i + 1
+		}
+	}
+	l.styles = append(l.styles, style)
+	return len(l.styles)
+}
+
+// Sequence returns what a number counts in and its value, so that consecutive entries can
+// be checked for gaps: "1.2" is 2 in the sections of chapter 1 and "Part II" is 2 in the parts.
+func Sequence(label string) (string, int, bool) {
+	label = strings.TrimRight(strings.TrimSpace(label), ".:")
+	if m := keywordRegex.FindStringSubmatch(label); m != nil {
+		n, ok := numberValue(strings.TrimSpace(label[len(m[0]):]))
+		return strings.ToLower(m[1]), n, ok
+	}
+	if decimalRegex.MatchString(label) {
+		i := strings.LastIndex(label, ".")
+		n, ok := numberValue(label[i+1:])
+		return decimalStyle(label) + " " + label[:max(i, 0)], n, ok
+	}
+	n, ok := numberValue(label)
+	return numberStyle(label), n, ok
+}
+
+// numberValue returns the value of an arabic number, a roman numeral or a letter counting from A.
+func numberValue(s string) (int, bool) {
+	if n, ok := utils.ParseNumber(s); ok {
+		return n, true
+	}
+	if len(s) == 1 && s[0] >= 'A' && s[0] <= 'Z' && !strings.Contains("IVX", s) {
+		return int(s[0]-'A') + 1, true
+	}
+	if n := parseRoman(s); n > 0 && s == strings.ToUpper(s) {
+		return n, true
+	}
+	return 0, false
+}
@@ -0,0 +1,152 @@
+package toc
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchNumbering(t *testing.T) {
+	tests := []struct {
+		line      string
+		wantLabel string
+		wantStyle string
+		wantRoman int
+		wantRest  string
+		wantOK    bool
+	}{
+		{"1. Rivers", "1", "decimal", 0, "Rivers", true},
+		{"1.2 Floods", "1.2", "decimal.", 0, "Floods", true},
+		{"1.2.3. Peaks", "1.2.3", "decimal..", 0, "Peaks", true},
+		{"१२. नदियाँ", "१२", "decimal", 0, "नदियाँ", true},
+		{"IV. Deltas", "IV", "roman", 4, "Deltas", true},
+		{"Chapter 3: Wells", "Chapter 3", "chapter", 0, "Wells", true},
+		{"PART II – Groundwater", "PART II", "part", 0, "Groundwater", true},
+		{"Appendix A Tables", "Appendix A", "appendix", 0, "Tables", true},
+		{"C. Rao", "C", "roman", 100, "Rao", true},
+		{"1 Rivers", "", "", 0, "1 Rivers", false},
+		{"Rivers 1.", "", "", 0, "Rivers 1.", false},
+		{"Chapters of history", "", "", 0, "Chapters of history", false},
+	}
+	for _, tt := range tests {
+		n, rest, ok := matchNumbering(tt.line)
+		if n.label != tt.wantLabel || n.style != tt.wantStyle || n.roman != tt.wantRoman || rest != tt.wantRest || ok != tt.wantOK {
+			t.Errorf("matchNumbering(%q) = %+v, %q, %v, want %q %q %d, %q, %v", tt.line, n, rest, ok, tt.wantLabel, tt.wantStyle, tt.wantRoman, tt.wantRest, tt.wantOK)
+		}
+	}
+}
+
+func TestParseRoman(t *testing.T) {
+	tests := map[string]int{
+		"I":         1,
+		"iv":        4,
+		"IX":        9,
+		"XIV":       14,
+		"XL":        40,
+		"MCMXCIV":   1994,
+		"":          0,
+		"IIA":       0,
+		"Chapter 3": 0,
+	}
+	for s, want := range tests {
+		if got := parseRoman(s); got != want {
+			t.Errorf("parseRoman(%q) = %d, want %d", s, got, want)
+		}
+	}
+}
+
+func TestAcceptedRomans(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  map[int]bool
+	}{
+		{
+			name:  "sequence",
+			lines: []string{"I. Rivers", "A. Kumar", "II. Lakes", "III. Wells"},
+			want:  map[int]bool{0: true, 2: true, 3: true},
+		},
+		{
+			name:  "initials are not numerals",
+			lines: []string{"1. Rivers", "C. Rao", "2. Lakes", "V. Singh"},
+			want:  map[int]bool{},
+		},
+		{
+			name:  "an I without a II after it",
+			lines: []string{"I. Rivers", "A. Kumar"},
+			want:  map[int]bool{},
+		},
+		{
+			name:  "initials within the sequence",
+			lines: []string{"I. Rivers", "D. Rao", "II. Lakes", "V. Singh", "III. Wells"},
+			want:  map[int]bool{0: true, 2: true, 4: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := acceptedRomans(tt.lines); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("acceptedRomans() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLevels(t *testing.T) {
+	var l levels
+	styles := []string{"part", "chapter", "decimal.", "decimal.", "chapter", "part", "chapter"}
+	want := []int{1, 2, 3, 3, 2, 1, 2}
+	for i, style := range styles {
+		if got := l.level(style); got != want[i] {
+			t.Errorf("level(%q) at entry %d = %d, want %d", style, i, got, want[i])
+		}
+	}
+}
+
+func TestSequence(t *testing.T) {
+	tests := []struct {
+		label        string
+		wantSequence string
+		wantValue    int
+		wantOK       bool
+	}{
+		{"3", "decimal ", 3, true},
+		{"1.2", "decimal. 1", 2, true},
+		{"2.10.", "decimal. 2", 10, true},
+		{"१२", "decimal ", 12, true},
+		{"IV", "roman", 4, true},
+		{"Chapter 3", "chapter", 3, true},
+		{"Part II", "part", 2, true},
+		{"Appendix B", "appendix", 2, true},
+		{"Appendix V", "appendix", 5, true},
+		{"Section x", "section", 0, false},
+	}
+	for _, tt := range tests {
+		sequence, value, ok := Sequence(tt.label)
+		if sequence != tt.wantSequence || value != tt.wantValue || ok != tt.wantOK {
+			t.Errorf("Sequence(%q) = %q, %d, %v, want %q, %d, %v", tt.label, sequence, value, ok, tt.wantSequence, tt.wantValue, tt.wantOK)
+		}
+	}
+}
+
+func TestParseRomanNumbering(t *testing.T) {
+	parser, err := New(LayoutNumbered, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := parser.Parse(`I. Rivers of the Plains 3
+C. Rao
+II. Lakes 9
+V. Singh
+III. Wells 15
+D. Mehta`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []summary{
+		{Title: "Rivers of the Plains", Authors: "C. Rao", Page: 3, Number: "I", Level: 1},
+		{Title: "Lakes", Authors: "V. Singh", Page: 9, Number: "II", Level: 1},
+		{Title: "Wells", Authors: "D. Mehta", Page: 15, Number: "III", Level: 1},
+	}
+	if got := summarize(entries); !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() =\n%+v\nwant\n%+v", got, want)
+	}
+}
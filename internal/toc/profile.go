@@ -0,0 +1,549 @@
+package toc
+
+import (
+	"bufio"
+	"fmt"
+	"pdf-extractor/internal/models"
+	"pdf-extractor/internal/utils"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	AuthorLast  = "last"
+	AuthorFirst = "first"
+	AuthorNone  = "none"
+)
+
+var (
+	// authorListEndRegex matches a line of authors that continues on the next line
+	authorListEndRegex = regexp.MustCompile(`(?:[,&]|\s(?i:and))$`)
+	// authorListStartRegex matches a line continuing the authors of the line before
+	authorListStartRegex = regexp.MustCompile(`^(?:&|(?i:and)\s)`)
+)
+
+// defaultPage matches a page number or range at the end of a line, after spaces or dot
+// leaders as in "Introduction ........ 12", or on a line of its own
+const defaultPage = `(?:^|[\s.·…]+)(\p{Nd}+)(?:\s*[-–]\s*\p{Nd}+)?$`
+
+// Profile describes how the entries of a table of contents are laid out. Every pattern is a
+// regular expression matched against a single trimmed line.
+type Profile struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+	// Numbering starts an entry at every line numbered like "1.", "1.2", "IV.", "Chapter 3"
+	// or "Part II", recording the number and its depth.
+	Numbering bool `yaml:"numbering,omitempty"`
+	// EntryStart matches the first line of an entry. When it matches at the start of the
+	// line, like an entry number, the matched text is removed from the title, and its first
+	// group, if any, is recorded as the number of the entry.
+	EntryStart string `yaml:"entry_start,omitempty"`
+	// EntryEnd matches the last line of an entry. Without Numbering or EntryStart it defaults
+	// to a line carrying a page number.
+	EntryEnd string `yaml:"entry_end,omitempty"`
+	// Author matches the author of an entry, taking the first group when it has one. The
+	// matched text is removed and the rest of the line is kept in the title. Without it, or
+	// when no line matches, the author is found by AuthorPosition.
+	Author string `yaml:"author,omitempty"`
+	// AuthorPosition is the line of an entry holding the author: last (default), first or none
+	AuthorPosition string `yaml:"author_position,omitempty"`
+	// Page matches the page number of an entry in its first group, and is removed from the line.
+	// Defaults to a number at the end of a line.
+	Page string `yaml:"page,omitempty"`
+	// Section matches the heading of a section of the contents, such as "Book Reviews". Without
+	// it, a line between two entries that is neither part of the previous entry nor numbered is
+	// taken for a section heading.
+	Section string `yaml:"section,omitempty"`
+	// Columns is the number of columns the contents are set in, 1 by default
+	Columns int `yaml:"columns,omitempty"`
+	// ContentsKeywords are the headings the contents are looked for under, DefaultContentsKeywords by default
+	ContentsKeywords []string `yaml:"contents_keywords,omitempty"`
+}
+
+// profileParser parses a table of contents laid out as described by a profile.
+type profileParser struct {
+	profile        Profile
+	entryStart     *regexp.Regexp
+	entryEnd       *regexp.Regexp
+	author         *regexp.Regexp
+	page           *regexp.Regexp
+	section        *regexp.Regexp
+	authorPosition string
+	keywords       []string
+}
+
+// NewProfileParser compiles the patterns of a profile.
+func NewProfileParser(p Profile) (TOCParser, error) {
+	parser := &profileParser{profile: p, authorPosition: p.AuthorPosition, keywords: p.ContentsKeywords}
+	if len(parser.keywords) == 0 {
+		parser.keywords = DefaultContentsKeywords
+	}
+	if parser.authorPosition == "" {
+		parser.authorPosition = AuthorLast
+	}
+	if parser.authorPosition != AuthorLast && parser.authorPosition != AuthorFirst && parser.authorPosition != AuthorNone {
+		return nil, fmt.Errorf("layout '%s': unknown author_position '%s': expected '%s', '%s' or '%s'", p.Name, p.AuthorPosition, AuthorLast, AuthorFirst, AuthorNone)
+	}
+	if p.Columns < 0 {
+		return nil, fmt.Errorf("layout '%s': columns must not be negative", p.Name)
+	}
+
+	page := p.Page
+	if page == "" {
+		page = defaultPage
+	}
+	entryEnd := p.EntryEnd
+	if !p.Numbering && p.EntryStart == "" && entryEnd == "" {
+		entryEnd = page
+	}
+	patterns := []struct {
+		field string
+		expr  string
+		re    **regexp.Regexp
+	}{
+		{"entry_start", p.EntryStart, &parser.entryStart},
+		{"entry_end", entryEnd, &parser.entryEnd},
+		{"author", p.Author, &parser.author},
+		{"page", page, &parser.page},
+		{"section", p.Section, &parser.section},
+	}
+	for _, pattern := range patterns {
+		if pattern.expr == "" {
+			continue
+		}
+		re, err := regexp.Compile(pattern.expr)
+		if err != nil {
+			return nil, fmt.Errorf("layout '%s': invalid %s: %v", p.Name, pattern.field, err)
+		}
+		*pattern.re = re
+	}
+	if parser.page.NumSubexp() == 0 {
+		return nil, fmt.Errorf("layout '%s': page must capture the page number in a group", p.Name)
+	}
+	return parser, nil
+}
+
+func (p *profileParser) Name() string {
+	return p.profile.Name
+}
+
+func (p *profileParser) Columns() int {
+	return max(p.profile.Columns, 1)
+}
+
+func (p *profileParser) ContentsKeywords() []string {
+	return p.keywords
+}
+
+// Parse returns the articles of the table of contents. Lines above the first entry, such as
+// the heading of the page, are skipped, except for the heading of the first section.
+func (p *profileParser) Parse(content string) ([]Entry, error) {
+	var lines []string
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		lines = append(lines, strings.TrimSpace(scanner.Text()))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading content: %v", err)
+	}
+	romans := acceptedRomans(lines)
+
+	var entries []Entry
+	var entry []string      // The lines of the current entry
+	var raw []string        // The lines of the current entry as printed
+	var number *entryNumber // The number the current entry starts with, if any
+	var page int            // The printed page the current entry starts on, if listed on a line of its own
+	var depth levels        // The levels of the numbering styles seen so far
+	var section string      // The section the current entry is listed under
+	var lead string         // The line right above the first entry, which may head the first section
+	hasStart := p.entryStart != nil || p.profile.Numbering
+
+	// Without a pattern for the first line of an entry, entries start after the heading
+	started := !hasStart && !p.hasContentsHeading(content)
+
+	saveArticle := func() {
+		if len(entry) > 0 || number != nil {
+			article := p.article(entry, page)
+			if number != nil {
+				article.Number = number.label
+				article.Level = depth.level(number.style)
+				if article.Title == "" {
+					// An entry such as "Part II" without a title of its own
+					article.Title = number.label
+				}
+			}
+			article.Lines = raw
+			article.Section = section
+			entries = append(entries, article)
+			logrus.Debugf("Added article: Title='%s', Author='%s', Page=%d", article.Title, article.AuthorNames(), article.Page)
+		}
+		entry, raw, number, page = nil, nil, nil, 0 // Reset for the next article
+	}
+
+	for i, line := range lines {
+		logrus.Debugf("Scanning line: '%s'", line)
+
+		// Skip empty lines
+		if line == "" {
+			continue
+		}
+		if !hasStart && IsContentsHeading(line, p.keywords) {
+			started, entry, raw, page = true, nil, nil, 0
+			continue
+		}
+
+		if n, rest, ok := p.startEntry(line, romans[i]); ok {
+			// A new entry saves the previous one
+			saveArticle()
+			started = true
+			number, line = n, rest
+		} else if p.isSection(lines, i, romans, started, raw) {
+			saveArticle()
+			section = line
+			continue
+		} else if !started {
+			if hasStart && !IsContentsHeading(line, p.keywords) && p.nextStartsEntry(lines, i, romans) {
+				lead = line
+			}
+			continue
+		}
+		raw = append(raw, lines[i])
+
+		if text, n, ok := p.splitPage(line); ok && text == "" {
+			// A line with only a page number or range gives the page of the entry
+			if (len(entry) > 0 || number != nil) && page == 0 {
+				page = n
+			}
+		} else if line != "" {
+			entry = append(entry, line)
+		}
+
+		if p.entryEnd != nil && p.entryEnd.MatchString(line) {
+			saveArticle()
+		}
+	}
+	// Handle the last article
+	saveArticle()
+
+	// Once the contents turn out to have sections, the line above the first entry heads the first
+	if lead != "" && p.section == nil && slices.ContainsFunc(entries, func(e Entry) bool { return e.Section != "" }) {
+		for i := 0; i < len(entries) && entries[i].Section == ""; i++ {
+			entries[i].Section = lead
+		}
+	}
+	return entries, nil
+}
+
+// ParseEntry reads the lines as one entry: its number from the first line, if numbered, and
+// its title, authors and page the way Parse reads those of an entry.
+func (p *profileParser) ParseEntry(lines []string) Entry {
+	romans := acceptedRomans(lines)
+	var text []string
+	var number *entryNumber
+	page := 0
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if i == 0 {
+			if n, rest, ok := p.startEntry(line, romans[i]); ok {
+				number, line = n, rest
+			}
+		}
+		if t, n, ok := p.splitPage(line); ok && t == "" {
+			// A page number on a line of its own
+			if page == 0 {
+				page = n
+			}
+		} else if line != "" {
+			text = append(text, line)
+		}
+	}
+	entry := p.article(text, page)
+	if number != nil {
+		entry.Number = number.label
+		if entry.Title == "" {
+			entry.Title = number.label
+		}
+	}
+	entry.Lines = lines
+	return entry
+}
+
+// isSection reports whether line i heads a section rather than continuing the entry read from
+// the raw lines before it. With a section pattern only the lines it matches do. Otherwise the
+// line must be followed by an entry, and the entry before it must be complete: its page seen
+// and, for the author on the last line, a line after the page, which the section heading
+// must not continue as a list of authors, an affiliation or a page number.
+func (p *profileParser) isSection(lines []string, i int, romans map[int]bool, started bool, raw []string) bool {
+	line := lines[i]
+	if p.section != nil {
+		return p.section.MatchString(line)
+	}
+	if !started || len(raw) == 0 || !p.nextStartsEntry(lines, i, romans) {
+		return false
+	}
+	if _, _, ok := p.splitPage(line); ok || models.IsAffiliation(line) || models.IsEmailLine(line) || continuesAuthors(raw[len(raw)-1], line) {
+		return false
+	}
+	pageLine := -1
+	for k, l := range raw {
+		if _, _, ok := p.splitPage(l); ok {
+			pageLine = k
+		}
+	}
+	if pageLine < 0 {
+		return false
+	}
+	linesAfterPage := len(raw) - 1 - pageLine
+	return p.authorPosition != AuthorLast || linesAfterPage > 0
+}
+
+// nextStartsEntry reports whether the next line that is not empty after line i starts an entry.
+func (p *profileParser) nextStartsEntry(lines []string, i int, romans map[int]bool) bool {
+	for j := i + 1; j < len(lines); j++ {
+		if lines[j] != "" {
+			_, _, ok := p.startEntry(lines[j], romans[j])
+			return ok
+		}
+	}
+	return false
+}
+
+// Continuation returns the page without the contents heading above its first entry, and
+// whether that entry is numbered as the next in one of the sequences of the contents before
+// it, such as "V." after "IV.", "Chapter 4" after "Chapter 3" or "1.3" after "1.2". The other
+// lines above the entry are kept, since a title, author or page number may wrap onto the page.
+// Pages of the contents without numbered entries never continue it.
+func (p *profileParser) Continuation(contents string, page string) (string, bool) {
+	previous := strings.Split(contents, "\n")
+	next := strings.Split(page, "\n")
+	var lines []string
+	for _, line := range slices.Concat(previous, next) {
+		lines = append(lines, strings.TrimSpace(line))
+	}
+	// Roman numerals on the page continue the sequence of those before it
+	romans := acceptedRomans(lines)
+
+	last := map[string]int{}
+	for i := range previous {
+		if n, _, ok := p.startEntry(lines[i], romans[i]); ok && n != nil {
+			if sequence, value, ok := Sequence(n.label); ok {
+				last[sequence] = value
+			}
+		}
+	}
+
+	var kept []string
+	for i := range next {
+		k := len(previous) + i
+		n, _, ok := p.startEntry(lines[k], romans[k])
+		if !ok {
+			// A heading repeated on the page, or a line reading "(continued)", is not part of an entry
+			text := utils.NormalizeText(lines[k])
+			if !IsContentsHeading(lines[k], p.keywords) && (text == "" || !continuedRegex.MatchString(text)) {
+				kept = append(kept, next[i])
+			}
+			continue
+		}
+		rest := strings.Join(append(kept, next[i:]...), "\n")
+		if n == nil {
+			return rest, false
+		}
+		sequence, value, ok := Sequence(n.label)
+		return rest, ok && last[sequence] > 0 && value == last[sequence]+1
+	}
+	return strings.Join(kept, "\n"), false
+}
+
+// startEntry reports whether a line starts an entry, returning the number of the entry, if
+// any, and the rest of the line. Bare roman numerals only start an entry when acceptedRoman.
+func (p *profileParser) startEntry(line string, acceptedRoman bool) (*entryNumber, string, bool) {
+	if p.profile.Numbering {
+		if n, rest, ok := matchNumbering(line); ok && (n.roman == 0 || acceptedRoman) {
+			return &n, rest, true
+		}
+	}
+	if p.entryStart == nil {
+		return nil, line, false
+	}
+	m := p.entryStart.FindStringSubmatchIndex(line)
+	if m == nil {
+		return nil, line, false
+	}
+	var number *entryNumber
+	if len(m) > 2 && m[2] >= 0 {
+		label := strings.TrimRight(strings.TrimSpace(line[m[2]:m[3]]), ".:")
+		number = &entryNumber{label: label, style: numberStyle(label)}
+	}
+	if m[0] == 0 {
+		line = strings.TrimSpace(line[m[1]:])
+	}
+	return number, line, true
+}
+
+// article turns the lines of an entry into an article.
+func (p *profileParser) article(lines []string, page int) Entry {
+	lines, affiliationLines := p.splitAffiliations(lines)
+	titleLines, authorLines := p.splitAuthor(lines)
+
+	// The page number may follow the title or the author
+	for i := range titleLines {
+		var n int
+		var ok bool
+		titleLines[i], n, ok = p.splitPage(titleLines[i])
+		if ok && page == 0 {
+			page = n
+		}
+	}
+	for _, group := range [][]string{authorLines, affiliationLines} {
+		for i := range group {
+			if text, n, ok := p.splitPage(group[i]); ok && text != "" {
+				group[i] = text
+				if page == 0 {
+					page = n
+				}
+			}
+		}
+	}
+	authors := models.ParseAuthors(strings.Join(authorLines, ", "))
+	addAffiliations(authors, affiliationLines)
+
+	// Combine all title lines into a single title
+	title := strings.TrimSpace(strings.Join(titleLines, " "))
+	title = strings.TrimSuffix(title, ".")
+	// trim any trailing number or digits
+	title = utils.TrimTrailingNumber(title)
+	return Entry{
+		Article: models.Article{
+			Title:   title,
+			Authors: authors,
+			Page:    page,
+		},
+		TitleLines: len(titleLines),
+	}
+}
+
+// splitAuthor separates the author lines of an entry from its title lines.
+func (p *profileParser) splitAuthor(lines []string) (title []string, author []string) {
+	if p.author != nil {
+		for _, line := range lines {
+			m := p.author.FindStringSubmatchIndex(line)
+			if m == nil {
+				title = append(title, line)
+				continue
+			}
+			name := line[m[0]:m[1]]
+			if len(m) > 2 && m[2] >= 0 {
+				name = line[m[2]:m[3]]
+			}
+			author = append(author, strings.TrimSpace(name))
+			// The rest of the line, such as the title the author follows, stays in the title
+			if rest := strings.TrimSpace(line[:m[0]] + " " + line[m[1]:]); rest != "" {
+				title = append(title, rest)
+			}
+		}
+		if len(author) > 0 {
+			return title, author
+		}
+		title = nil
+	}
+
+	// A single line is the title
+	if len(lines) < 2 || p.authorPosition == AuthorNone {
+		return append(title, lines...), nil
+	}
+	if p.authorPosition == AuthorFirst {
+		end := 1
+		for end < len(lines)-1 && continuesAuthors(lines[end-1], lines[end]) {
+			end++
+		}
+		return append(title, lines[end:]...), lines[:end]
+	}
+	start := len(lines) - 1
+	for start > 1 && continuesAuthors(lines[start-1], lines[start]) {
+		start--
+	}
+	return append(title, lines[:start]...), lines[start:]
+}
+
+// continuesAuthors reports whether a list of authors wraps from one line to the next.
+func continuesAuthors(line, next string) bool {
+	return authorListEndRegex.MatchString(line) || authorListStartRegex.MatchString(next)
+}
+
+// splitAffiliations separates the affiliations and emails printed with the authors from the
+// other lines of an entry: the lines following the author for author_position first, and
+// the last lines of the entry otherwise.
+func (p *profileParser) splitAffiliations(lines []string) ([]string, []string) {
+	isAffiliation := func(line string) bool {
+		return models.IsAffiliation(line) || models.IsEmailLine(line)
+	}
+	if p.authorPosition == AuthorFirst {
+		end := 1
+		for end < len(lines)-1 && isAffiliation(lines[end]) {
+			end++
+		}
+		rest := append([]string{}, lines[:1]...)
+		return append(rest, lines[end:]...), lines[1:end]
+	}
+	start := len(lines)
+	for start > 1 && isAffiliation(lines[start-1]) {
+		start--
+	}
+	return lines[:start], lines[start:]
+}
+
+// addAffiliations gives the authors the affiliations and emails from the lines below them.
+// Affiliations are matched to the authors in order when there is one for every author, and
+// are otherwise shared by all of them.
+func addAffiliations(authors []models.Author, lines []string) {
+	var affiliations, emails []string
+	for _, line := range lines {
+		if models.IsEmailLine(line) {
+			emails = append(emails, models.Emails(line)...)
+		} else {
+			affiliations = append(affiliations, line)
+		}
+	}
+	for i := range authors {
+		if authors[i].Affiliation == "" && len(affiliations) > 0 {
+			if len(affiliations) == len(authors) {
+				authors[i].Affiliation = affiliations[i]
+			} else {
+				authors[i].Affiliation = strings.Join(affiliations, "; ")
+			}
+		}
+		if authors[i].Email == "" && i < len(emails) {
+			authors[i].Email = emails[i]
+		}
+	}
+}
+
+// splitPage splits a line into its text and the page number in it, if any.
+func (p *profileParser) splitPage(line string) (string, int, bool) {
+	m := p.page.FindStringSubmatchIndex(line)
+	if m == nil {
+		return line, 0, false
+	}
+	for g := 2; g < len(m); g += 2 {
+		if m[g] < 0 {
+			continue
+		}
+		if n, ok := utils.ParseNumber(line[m[g]:m[g+1]]); ok {
+			return strings.TrimSpace(line[:m[0]] + line[m[1]:]), n, true
+		}
+	}
+	return line, 0, false
+}
+
+// hasContentsHeading reports whether a line of the content is a contents heading.
+func (p *profileParser) hasContentsHeading(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		if IsContentsHeading(line, p.keywords) {
+			return true
+		}
+	}
+	return false
+}
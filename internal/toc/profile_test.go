@@ -0,0 +1,433 @@
+package toc
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// summary is what a test expects of an entry.
+type summary struct {
+	Title   string
+	Authors string
+	Page    int
+	Number  string
+	Level   int
+	Section string
+}
+
+func summarize(entries []Entry) []summary {
+	var got []summary
+	for _, e := range entries {
+		got = append(got, summary{e.Title, e.AuthorNames(), e.Page, e.Number, e.Level, e.Section})
+	}
+	return got
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		layout  string
+		content string
+		want    []summary
+	}{
+		{
+			name:   "numbered",
+			layout: LayoutNumbered,
+			content: `Contents
+1. Rivers of the Plains 3
+A. Kumar
+2. Groundwater Recharge in
+Dry Regions 15
+B. Singh and C. Rao`,
+			want: []summary{
+				{Title: "Rivers of the Plains", Authors: "A. Kumar", Page: 3, Number: "1", Level: 1},
+				{Title: "Groundwater Recharge in Dry Regions", Authors: "B. Singh, C. Rao", Page: 15, Number: "2", Level: 1},
+			},
+		},
+		{
+			name:   "numbered with the page on a line of its own",
+			layout: LayoutNumbered,
+			content: `1. Rivers of the Plains
+3
+A. Kumar`,
+			want: []summary{{Title: "Rivers of the Plains", Authors: "A. Kumar", Page: 3, Number: "1", Level: 1}},
+		},
+		{
+			name:   "author first",
+			layout: LayoutAuthorFirst,
+			content: `Contents
+1. A. Kumar
+Rivers of the Plains 3
+2. B. Singh,
+C. Rao
+Groundwater Recharge 15`,
+			want: []summary{
+				{Title: "Rivers of the Plains", Authors: "A. Kumar", Page: 3, Number: "1", Level: 1},
+				{Title: "Groundwater Recharge", Authors: "B. Singh, C. Rao", Page: 15, Number: "2", Level: 1},
+			},
+		},
+		{
+			name:   "dotted",
+			layout: LayoutDotted,
+			content: `Table of Contents
+Rivers of the Plains .......... 3
+A. Kumar
+Groundwater Recharge … 15
+B. Singh`,
+			want: []summary{
+				{Title: "Rivers of the Plains", Authors: "A. Kumar", Page: 3},
+				{Title: "Groundwater Recharge", Authors: "B. Singh", Page: 15},
+			},
+		},
+		{
+			name:   "unnumbered",
+			layout: LayoutUnnumbered,
+			content: `Journal of Hydrology
+Contents
+Rivers of the Plains
+A. Kumar 3
+Groundwater Recharge in
+Dry Regions
+B. Singh 15`,
+			want: []summary{
+				{Title: "Rivers of the Plains", Authors: "A. Kumar", Page: 3},
+				{Title: "Groundwater Recharge in Dry Regions", Authors: "B. Singh", Page: 15},
+			},
+		},
+		{
+			name:   "affiliations and emails below the author",
+			layout: LayoutNumbered,
+			content: `1. Rivers of the Plains 3
+A. Kumar
+University of Delhi
+a.kumar@example.org`,
+			want: []summary{{Title: "Rivers of the Plains", Authors: "A. Kumar", Page: 3, Number: "1", Level: 1}},
+		},
+		{
+			name:   "sections",
+			layout: LayoutNumbered,
+			content: `Contents
+Research Articles
+1. Rivers of the Plains 3
+A. Kumar
+Book Reviews
+2. Water in History 40
+B. Singh`,
+			want: []summary{
+				{Title: "Rivers of the Plains", Authors: "A. Kumar", Page: 3, Number: "1", Level: 1, Section: "Research Articles"},
+				{Title: "Water in History", Authors: "B. Singh", Page: 40, Number: "2", Level: 1, Section: "Book Reviews"},
+			},
+		},
+		{
+			name:   "nested numbering",
+			layout: LayoutNumbered,
+			content: `Part I Surface Water
+1. Rivers 3
+A. Kumar
+1.1 Floods 9
+B. Singh
+Part II Groundwater
+2. Aquifers 20
+C. Rao`,
+			want: []summary{
+				{Title: "Surface Water", Number: "Part I", Level: 1},
+				{Title: "Rivers", Authors: "A. Kumar", Page: 3, Number: "1", Level: 2},
+				{Title: "Floods", Authors: "B. Singh", Page: 9, Number: "1.1", Level: 3},
+				{Title: "Groundwater", Number: "Part II", Level: 1},
+				{Title: "Aquifers", Authors: "C. Rao", Page: 20, Number: "2", Level: 2},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser, err := New(tt.layout, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			entries, err := parser.Parse(tt.content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := summarize(entries); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCustomProfile(t *testing.T) {
+	parser, err := NewProfileParser(Profile{
+		Name:       "journal",
+		EntryStart: `^Art\.\s*(\p{Nd}+)`,
+		Author:     `\s+by\s+(.+)$`,
+		Section:    `^[A-Z ]+$`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := parser.Parse(`RESEARCH
+Art. 1 Rivers of the Plains by A. Kumar 3
+Art. 2 Groundwater Recharge by B. Singh 15`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []summary{
+		{Title: "Rivers of the Plains", Authors: "A. Kumar", Page: 3, Number: "1", Level: 1, Section: "RESEARCH"},
+		{Title: "Groundwater Recharge", Authors: "B. Singh", Page: 15, Number: "2", Level: 1, Section: "RESEARCH"},
+	}
+	if got := summarize(entries); !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestNewProfileParserErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile Profile
+		want    string
+	}{
+		{"author position", Profile{Name: "p", AuthorPosition: "middle"}, "unknown author_position 'middle'"},
+		{"columns", Profile{Name: "p", Columns: -1}, "columns must not be negative"},
+		{"invalid pattern", Profile{Name: "p", EntryStart: "("}, "invalid entry_start"},
+		{"page without a group", Profile{Name: "p", Page: `\d+$`}, "page must capture the page number"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewProfileParser(tt.profile)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("NewProfileParser() error = %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	dir := t.TempDir()
+	profile := filepath.Join(dir, "profile.yaml")
+	if err := os.WriteFile(profile, []byte("numbering: true\ncontents_keywords: [Index]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	typo := filepath.Join(dir, "typo.yaml")
+	if err := os.WriteFile(typo, []byte("numbered: true\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	parser, err := New(profile, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parser.Name() != profile || !reflect.DeepEqual(parser.ContentsKeywords(), []string{"Index"}) || parser.Columns() != 1 {
+		t.Errorf("profile read as %q with keywords %q and %d columns", parser.Name(), parser.ContentsKeywords(), parser.Columns())
+	}
+	if parser, err = New(LayoutTwoColumn, []string{"Inhalt"}); err != nil || parser.Columns() != 2 || !reflect.DeepEqual(parser.ContentsKeywords(), []string{"Inhalt"}) {
+		t.Errorf("two-column layout = %v, %v", parser, err)
+	}
+	if _, err := New(typo, nil); err == nil {
+		t.Error("a profile with an unknown key was accepted")
+	}
+	if _, err := New("numbers", nil); err == nil || !strings.Contains(err.Error(), "unknown layout 'numbers'") {
+		t.Errorf("New(\"numbers\") error = %v", err)
+	}
+}
+
+func TestIsContentsHeading(t *testing.T) {
+	tests := []struct {
+		line string
+		want bool
+	}{
+		{"Contents", true},
+		{"TABLE OF CONTENTS", true},
+		{"Contents (continued)", true},
+		{"Contents contd.", true},
+		{"विषय सूची", true},
+		{"All contents copyrighted", false},
+		{"Contents of the survey", false},
+	}
+	for _, tt := range tests {
+		if got := IsContentsHeading(tt.line, DefaultContentsKeywords); got != tt.want {
+			t.Errorf("IsContentsHeading(%q) = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestContinuation(t *testing.T) {
+	numbered, err := New(LayoutNumbered, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unnumbered, err := New(LayoutUnnumbered, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		parser   TOCParser
+		contents string
+		page     string
+		wantRest string
+		wantOK   bool
+	}{
+		{
+			name:     "arabic",
+			parser:   numbered,
+			contents: "1. Rivers 3\nA. Kumar\n2. Lakes 9\nB. Singh",
+			page:     "Contents (continued)\n3. Wells 15\nC. Rao",
+			wantRest: "3. Wells 15\nC. Rao",
+			wantOK:   true,
+		},
+		{
+			name:     "entry wrapping across the page break",
+			parser:   numbered,
+			contents: "1. Rivers of the",
+			page:     "Plains 3\nA. Kumar\n2. Lakes 9\nB. Singh",
+			wantRest: "Plains 3\nA. Kumar\n2. Lakes 9\nB. Singh",
+			wantOK:   true,
+		},
+		{
+			name:     "continued marker",
+			parser:   numbered,
+			contents: "1. Rivers 3\nA. Kumar",
+			page:     "(continued)\n\nKumar\n2. Lakes 9",
+			wantRest: "\nKumar\n2. Lakes 9",
+			wantOK:   true,
+		},
+		{
+			name:     "roman",
+			parser:   numbered,
+			contents: "I. Rivers 3\nII. Lakes 9\nIII. Springs 12\nIV. Deltas 14",
+			page:     "V. Wells 15",
+			wantRest: "V. Wells 15",
+			wantOK:   true,
+		},
+		{
+			name:     "chapters",
+			parser:   numbered,
+			contents: "Chapter 1 Rivers 3\nChapter 2 Lakes 9",
+			page:     "Chapter 3 Wells 15",
+			wantRest: "Chapter 3 Wells 15",
+			wantOK:   true,
+		},
+		{
+			name:     "decimal",
+			parser:   numbered,
+			contents: "1. Water 1\n1.1 Rivers 3\n1.2 Lakes 9",
+			page:     "1.3 Wells 15",
+			wantRest: "1.3 Wells 15",
+			wantOK:   true,
+		},
+		{
+			name:     "gap in the numbering",
+			parser:   numbered,
+			contents: "1. Rivers 3\n2. Lakes 9",
+			page:     "7. Results 15",
+			wantRest: "7. Results 15",
+		},
+		{
+			name:     "numbering starting over",
+			parser:   numbered,
+			contents: "1. Rivers 3\n2. Lakes 9",
+			page:     "1. Introduction 1",
+			wantRest: "1. Introduction 1",
+		},
+		{
+			name:     "no entry on the page",
+			parser:   numbered,
+			contents: "1. Rivers 3",
+			page:     "Results\nThe rivers",
+			wantRest: "Results\nThe rivers",
+		},
+		{
+			name:     "heading of a page without entries",
+			parser:   numbered,
+			contents: "1. Rivers 3",
+			page:     "Contents\nResults",
+			wantRest: "Results",
+		},
+		{
+			name:     "unnumbered contents",
+			parser:   unnumbered,
+			contents: "Rivers\nA. Kumar 3",
+			page:     "Lakes\nB. Singh 9",
+			wantRest: "Lakes\nB. Singh 9",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rest, ok := tt.parser.Continuation(tt.contents, tt.page)
+			if rest != tt.wantRest || ok != tt.wantOK {
+				t.Errorf("Continuation() = %q, %v, want %q, %v", rest, ok, tt.wantRest, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestParseAcrossPages(t *testing.T) {
+	parser, err := New(LayoutNumbered, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	contents := "Contents\n1. Rivers of the"
+	rest, ok := parser.Continuation(contents, "Contents (continued)\nPlains 3\nA. Kumar\n2. Lakes 9\nB. Singh")
+	if !ok {
+		t.Fatal("the page does not continue the contents")
+	}
+	entries, err := parser.Parse(contents + "\n" + rest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []summary{
+		{Title: "Rivers of the Plains", Authors: "A. Kumar", Page: 3, Number: "1", Level: 1},
+		{Title: "Lakes", Authors: "B. Singh", Page: 9, Number: "2", Level: 1},
+	}
+	if got := summarize(entries); !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestParseEntry(t *testing.T) {
+	tests := []struct {
+		name   string
+		layout string
+		lines  []string
+		want   summary
+	}{
+		{
+			name:   "without the number that starts an entry",
+			layout: LayoutNumbered,
+			lines:  []string{"Lakes of the North 9", "B. Singh"},
+			want:   summary{Title: "Lakes of the North", Authors: "B. Singh", Page: 9},
+		},
+		{
+			name:   "numbered",
+			layout: LayoutNumbered,
+			lines:  []string{"4. Lakes", "B. Singh", "9"},
+			want:   summary{Title: "Lakes", Authors: "B. Singh", Page: 9, Number: "4"},
+		},
+		{
+			name:   "author first",
+			layout: LayoutAuthorFirst,
+			lines:  []string{"B. Singh", "Lakes of the North 9"},
+			want:   summary{Title: "Lakes of the North", Authors: "B. Singh", Page: 9},
+		},
+		{
+			name:   "title only",
+			layout: LayoutUnnumbered,
+			lines:  []string{"Lakes of the North"},
+			want:   summary{Title: "Lakes of the North"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser, err := New(tt.layout, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := summarize([]Entry{parser.ParseEntry(tt.lines)}); !reflect.DeepEqual(got, []summary{tt.want}) {
+				t.Errorf("ParseEntry(%q) = %+v, want %+v", tt.lines, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package toc
+
+import (
+	"fmt"
+	"os"
+	"pdf-extractor/internal/models"
+	"pdf-extractor/internal/utils"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	LayoutNumbered    = "numbered"
+	LayoutAuthorFirst = "author-first"
+	LayoutDotted      = "dotted"
+	LayoutUnnumbered  = "unnumbered"
+	LayoutTwoColumn   = "two-column"
+)
+
+// TOCParser reads the articles listed in the text of a table of contents.
+type TOCParser interface {
+	// Name returns the layout name as accepted by --layout
+	Name() string
+	// Columns returns how many columns the contents are set in, which decides the order their lines are read in
+	Columns() int
+	// ContentsKeywords returns the headings the contents are looked for under
+	ContentsKeywords() []string
+	// Continuation returns a page of the contents without its heading, and whether its first
+	// entry continues the numbering of the contents read so far
+	Continuation(contents string, page string) (string, bool)
+	Parse(content string) ([]Entry, error)
+	// ParseEntry reads lines of the contents as a single entry, even when they would not start
+	// one, as when an entry is split in two
+	ParseEntry(lines []string) Entry
+}
+
+// Entry is an article read from the contents, along with how it was read.
+type Entry struct {
+	models.Article
+	// TitleLines is the number of lines the title was joined from
+	TitleLines int
+	// Lines are the lines of the contents the entry was read from
+	Lines []string
+}
+
+// DefaultContentsKeywords are the headings the contents are looked for under unless the
+// layout profile or --contents-keyword gives others.
+var DefaultContentsKeywords = []string{"Contents", "Table of Contents", "In This Issue", "विषय सूची", "अनुक्रमणिका"}
+
+// continuedRegex matches what may follow a contents heading on the pages it continues on.
+var continuedRegex = regexp.MustCompile(`^(?:continued|contd)?$`)
+
+// IsContentsHeading reports whether a line reads like one of the keywords, possibly followed
+// by "(continued)". Mentions such as "All contents copyrighted" are not headings.
+func IsContentsHeading(line string, keywords []string) bool {
+	normalized := utils.NormalizeText(line)
+	for _, keyword := range keywords {
+		keyword = utils.NormalizeText(keyword)
+		if keyword != "" && strings.HasPrefix(normalized, keyword) && continuedRegex.MatchString(normalized[len(keyword):]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Built-in profiles selectable with --layout.
+var profiles = map[string]Profile{
+	LayoutNumbered: {
+		Name:        LayoutNumbered,
+		Description: "numbered entries (\"1.\", \"1.2\", \"IV.\", \"Chapter 3\") with the title first and the author on the last line",
+		Numbering:   true,
+	},
+	LayoutAuthorFirst: {
+		Name:           LayoutAuthorFirst,
+		Description:    "numbered entries with the author on the first line, followed by the title",
+		Numbering:      true,
+		AuthorPosition: AuthorFirst,
+	},
+	LayoutDotted: {
+		Name:        LayoutDotted,
+		Description: "a title joined to its page number by dot leaders, with the author on the lines below",
+		EntryStart:  `(?:\.\s*){3,}\p{Nd}+$|…\s*\p{Nd}+$`,
+	},
+	LayoutUnnumbered: {
+		Name:        LayoutUnnumbered,
+		Description: "unnumbered entries with the title first, each ending with the page number after the author",
+		EntryEnd:    defaultPage,
+	},
+	LayoutTwoColumn: {
+		Name:        LayoutTwoColumn,
+		Description: "numbered entries like numbered, set in two columns",
+		Numbering:   true,
+		Columns:     2,
+	},
+}
+
+// Layouts returns the names of the built-in profiles.
+func Layouts() []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New returns the parser for a built-in layout, or for the custom profile in the YAML file at
+// layout. Contents keywords, when given, replace those of the profile.
+func New(layout string, contentsKeywords []string) (TOCParser, error) {
+	p, ok := profiles[layout]
+	if !ok {
+		if !strings.HasSuffix(layout, ".yaml") && !strings.HasSuffix(layout, ".yml") {
+			return nil, fmt.Errorf("unknown layout '%s': expected one of %s, or the path to a YAML profile", layout, strings.Join(Layouts(), ", "))
+		}
+		var err error
+		if p, err = LoadProfile(layout); err != nil {
+			return nil, err
+		}
+	}
+	if len(contentsKeywords) > 0 {
+		p.ContentsKeywords = contentsKeywords
+	}
+	return NewProfileParser(p)
+}
+
+// LoadProfile reads a custom profile from a YAML file.
+func LoadProfile(filePath string) (Profile, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return Profile{}, fmt.Errorf("failed to read layout profile: %v", err)
+	}
+	var p Profile
+	if err := yaml.UnmarshalStrict(data, &p); err != nil {
+		return Profile{}, fmt.Errorf("failed to parse layout profile %s: %v", filePath, err)
+	}
+	if p.Name == "" {
+		p.Name = filePath
+	}
+	return p, nil
+}
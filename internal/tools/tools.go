@@ -0,0 +1,134 @@
+package tools
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Tool describes an external command line tool pdf-extractor can make use of.
+type Tool struct {
+	Name        string
+	VersionArgs []string
+	Capability  string
+	Install     string
+}
+
+// Known lists every external tool in the order it is reported by the doctor command.
+var Known = []Tool{
+	{
+		Name:        "pdfinfo",
+		VersionArgs: []string{"-v"},
+		Capability:  "Page counts for --text-engine=pdftotext",
+		Install:     "install poppler (apt install poppler-utils, brew install poppler or choco install poppler)",
+	},
+	{
+		Name:        "pdftotext",
+		VersionArgs: []string{"-v"},
+		Capability:  "Page text for --text-engine=pdftotext",
+		Install:     "install poppler (apt install poppler-utils, brew install poppler or choco install poppler)",
+	},
+	{
+		Name:        "pdftk",
+		VersionArgs: []string{"--version"},
+		Capability:  "Extracting and deleting pages for --page-writer=pdftk",
+		Install:     "install pdftk (apt install pdftk, brew install pdftk-java or choco install pdftk)",
+	},
+	{
+		Name:        "pdftoppm",
+		VersionArgs: []string{"-v"},
+		Capability:  "Rendering scanned pages for --ocr",
+		Install:     "install poppler (apt install poppler-utils, brew install poppler or choco install poppler)",
+	},
+	{
+		Name:        "tesseract",
+		VersionArgs: []string{"--version"},
+		Capability:  "Recognizing text on scanned pages for --ocr",
+		Install:     "install tesseract (apt install tesseract-ocr, brew install tesseract or choco install tesseract)",
+	},
+}
+
+// Requirer is implemented by backends that shell out to external tools.
+type Requirer interface {
+	RequiredTools() []string
+}
+
+// Status is the result of looking up a tool in PATH.
+type Status struct {
+	Tool
+	Path    string
+	Version string
+	Found   bool
+}
+
+// Find returns the tool with the given name from Known.
+func Find(name string) Tool {
+	for _, t := range Known {
+		if t.Name == name {
+			return t
+		}
+	}
+	return Tool{Name: name, VersionArgs: []string{"--version"}}
+}
+
+// Required returns the tools needed by the given backends, without duplicates.
+func Required(backends ...interface{}) []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, b := range backends {
+		r, ok := b.(Requirer)
+		if !ok {
+			continue
+		}
+		for _, name := range r.RequiredTools() {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// Lookup finds a tool in PATH and reads its version.
+func Lookup(t Tool) Status {
+	s := Status{Tool: t}
+	path, err := exec.LookPath(t.Name)
+	if err != nil {
+		return s
+	}
+	s.Path, s.Found = path, true
+
+	// Several tools print their version to stderr or exit non-zero, so only the output matters
+	output, _ := exec.Command(path, t.VersionArgs...).CombinedOutput()
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			s.Version = line
+			break
+		}
+	}
+	return s
+}
+
+// Check returns an error naming every missing tool and how to install it.
+func Check(names []string) error {
+	var hints []string
+	missing := map[string][]string{}
+	for _, name := range names {
+		t := Find(name)
+		if s := Lookup(t); !s.Found {
+			if _, ok := missing[t.Install]; !ok {
+				hints = append(hints, t.Install)
+			}
+			missing[t.Install] = append(missing[t.Install], t.Name)
+		}
+	}
+	if len(hints) == 0 {
+		return nil
+	}
+	var parts []string
+	for _, hint := range hints {
+		parts = append(parts, fmt.Sprintf("%s not found in PATH, %s", strings.Join(missing[hint], " and "), hint))
+	}
+	return fmt.Errorf("%s. Run 'pdf-extractor doctor' for details", strings.Join(parts, "; "))
+}
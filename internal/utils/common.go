@@ -4,9 +4,13 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"os/exec"
 	"regexp"
+	"strconv"
 	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
 )
 
 func RecreateDirectory(path string) error {
@@ -43,69 +47,38 @@ func CheckFileExists(filePath string) error {
 	return nil // File exists
 }
 
-func GetPDFPageCount(pdfPath string) (int, error) {
-	// Run the pdfinfo command to get the total number of pages
-	fmt.Println("PDF Path:", pdfPath)
-	cmd := exec.Command("pdfinfo", pdfPath)
-	output, err := cmd.Output()
-	if err != nil {
-		return 0, fmt.Errorf("failed to run pdfinfo: %v", err)
-	}
-
-	// Parse the output to find the "Pages" line
-	var totalPages int
-	lines := string(output)
-	for _, line := range strings.Split(lines, "\n") {
-		if strings.HasPrefix(line, "Pages:") {
-			_, err := fmt.Sscanf(line, "Pages: %d", &totalPages)
-			if err != nil {
-				return 0, fmt.Errorf("failed to parse page count: %v", err)
+// NormalizeText reduces text to case folded letters, marks and digits so that titles
+// can be compared regardless of case, accents, punctuation and spacing.
+func NormalizeText(text string) string {
+	// Decompose compatibility characters and ligatures, splitting accents from their letters
+	text = cases.Fold().String(norm.NFKD.String(text))
+
+	var b strings.Builder
+	var base rune
+	for _, r := range text {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			base = r
+			b.WriteRune(r)
+		case unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Mc, r):
+			// Accents are dropped from Latin, Greek and Cyrillic letters, but marks such
+			// as Devanagari vowel signs are part of the spelling and are kept
+			if !unicode.In(base, unicode.Latin, unicode.Greek, unicode.Cyrillic) {
+				b.WriteRune(r)
 			}
-			break
 		}
 	}
 
-	return totalPages, nil
-}
-func ExtractPDFPageWithPdftotext(pdfPath, outputPath string, page int) error {
-	// Run the pdftotext command for a specific page in raw mode
-	cmd := exec.Command("pdftotext", "-layout", "-f", fmt.Sprintf("%d", page), "-l", fmt.Sprintf("%d", page), pdfPath, outputPath)
-	cmd.Stdout = nil
-	cmd.Stderr = nil
-
-	err := cmd.Run()
-	if err != nil {
-		return fmt.Errorf("failed to extract text using pdftotext: %v", err)
-	}
-
-	return nil
-}
-func NormalizeText(text string) string {
-	// Convert to lowercase
-	text = strings.ToLower(text)
-
-	// Remove leading and trailing spaces
-	text = strings.TrimSpace(text)
-
-	// Allow specific characters (a-z, 0-9, space, ',', ':', ';', '-')
-	text = strings.Map(func(r rune) rune {
-		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
-			return r
-		}
-		return -1
-	}, text)
-
-	// Replace multiple spaces with a single space
-	text = strings.Join(strings.Fields(text), " ")
-
-	return text
+	// Recompose what is left, so that equal text is always encoded the same way
+	return norm.NFC.String(b.String())
 }
 func TrimTrailingNumber(title string) string {
 	// Regular expression to match trailing numbers and spaces
-	re := regexp.MustCompile(`\s*\d+$`)
+	re := regexp.MustCompile(`\s*\p{Nd}+$`)
 	// Replace trailing numbers and spaces with an empty string
 	return strings.TrimSpace(re.ReplaceAllString(title, ""))
 }
+
 func Min(a, b int) int {
 	if a < b {
 		return a
@@ -117,18 +90,10 @@ func SanitizeFileName(name string) string {
 	// Replace spaces with underscores first
 	name = strings.ReplaceAll(name, " ", "_")
 	// Remove all special characters apart from underscores
-	name = regexp.MustCompile(`[^a-zA-Z0-9_]`).ReplaceAllString(name, "")
+	name = regexp.MustCompile(`[^\p{L}\p{M}\p{N}_]`).ReplaceAllString(name, "")
 	return name
 }
 
-func RemoveDigits(input string) string {
-	re := regexp.MustCompile("[0-9]")
-	return re.ReplaceAllString(input, "")
-}
-func IsDigit(ch byte) bool {
-	return ch >= '0' && ch <= '9'
-}
-
 func CopyFile(src, dst string) error {
 	input, err := os.Open(src)
 	if err != nil {
@@ -152,3 +117,43 @@ func DeleteFile(filePath string) error {
 	}
 	return nil
 }
+
+// ParseNumber parses a non-negative integer written in the digits of any script.
+func ParseNumber(s string) (int, bool) {
+	if s == "" {
+		return 0, false
+	}
+	n := 0
+	for _, r := range s {
+		if !unicode.Is(unicode.Nd, r) {
+			return 0, false
+		}
+		// Decimal digits are encoded in runs of ten starting at zero
+		zero := r
+		for unicode.Is(unicode.Nd, zero-1) && r-zero < 9 {
+			zero--
+		}
+		n = n*10 + int(r-zero)
+	}
+	return n, true
+}
+
+// ParsePageRange parses a page range such as "3-5", or a single page such as "3".
+func ParsePageRange(s string) (int, int, error) {
+	first, last, isRange := strings.Cut(strings.TrimSpace(s), "-")
+	from, err := strconv.Atoi(strings.TrimSpace(first))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid page range '%s': expected a page such as 3 or a range such as 3-5", s)
+	}
+	to := from
+	if isRange {
+		to, err = strconv.Atoi(strings.TrimSpace(last))
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid page range '%s': expected a page such as 3 or a range such as 3-5", s)
+		}
+	}
+	if from < 1 || to < from {
+		return 0, 0, fmt.Errorf("invalid page range '%s': pages start at 1 and the range must not end before it starts", s)
+	}
+	return from, to, nil
+}
@@ -0,0 +1,57 @@
+package utils
+
+import "testing"
+
+func TestNormalizeText(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"case and spacing", "Water  Management", "watermanagement"},
+		{"punctuation", "Rivers, Lakes & Wells: A Survey", "riverslakeswellsasurvey"},
+		{"Latin accents", "Économie du café", "economieducafe"},
+		{"German sharp s", "STRASSE straße", "strassestrasse"},
+		{"ligatures", "ﬁnancial ﬂows", "financialflows"},
+		{"Greek accents", "Ἀθῆναι", "αθηναι"},
+		{"Cyrillic", "Ёлка", "елка"},
+		{"Devanagari vowel signs are kept", "हिंदी भाषा", "हिंदीभाषा"},
+		{"digits of any script", "Chapter ३ 12", "chapter३12"},
+		{"empty", " - ", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeText(tt.text); got != tt.want {
+				t.Errorf("NormalizeText(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeTextComposition(t *testing.T) {
+	// The same Devanagari text written precomposed and decomposed normalizes the same way
+	precomposed, decomposed := "क़", "क़"
+	if NormalizeText(precomposed) != NormalizeText(decomposed) {
+		t.Errorf("NormalizeText(%q) = %q, NormalizeText(%q) = %q", precomposed, NormalizeText(precomposed), decomposed, NormalizeText(decomposed))
+	}
+}
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+		ok   bool
+	}{
+		{"12", 12, true},
+		{"०३", 3, true},
+		{"١٢", 12, true},
+		{"", 0, false},
+		{"12a", 0, false},
+		{"-1", 0, false},
+	}
+	for _, tt := range tests {
+		if got, ok := ParseNumber(tt.s); got != tt.want || ok != tt.ok {
+			t.Errorf("ParseNumber(%q) = %d, %v, want %d, %v", tt.s, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"context"
+	"sync"
+)
+
+// RunParallel calls fn for every index in [0, n) using at most jobs workers.
+// Callers keep results in order by writing them to index i of a slice.
+// The first error cancels the context passed to fn, stops handing out the
+// remaining indexes and is returned once the running calls have finished.
+func RunParallel(jobs, n int, fn func(ctx context.Context, i int) error) error {
+	if jobs < 1 {
+		jobs = 1
+	}
+	if jobs > n {
+		jobs = n
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+		indexes  = make(chan int)
+	)
+	for w := 0; w < jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				// An index handed out as the first error came in is not worked on
+				if ctx.Err() != nil {
+					continue
+				}
+				if err := fn(ctx, i); err != nil {
+					once.Do(func() {
+						firstErr = err
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+
+dispatch:
+	for i := 0; i < n; i++ {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(indexes)
+	wg.Wait()
+	return firstErr
+}
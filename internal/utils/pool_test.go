@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunParallelOrder(t *testing.T) {
+	for _, jobs := range []int{0, 1, 2, 4, 100} {
+		t.Run(fmt.Sprintf("jobs=%d", jobs), func(t *testing.T) {
+			results := make([]int, 20)
+			err := RunParallel(jobs, len(results), func(_ context.Context, i int) error {
+				// Later indexes finish first
+				time.Sleep(time.Duration(len(results)-i) * 100 * time.Microsecond)
+				results[i] = i * i
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("RunParallel() error = %v", err)
+			}
+			for i, r := range results {
+				if r != i*i {
+					t.Fatalf("results = %v, want the square of every index in order", results)
+				}
+			}
+		})
+	}
+}
+
+func TestRunParallelJobs(t *testing.T) {
+	for _, jobs := range []int{1, 3} {
+		t.Run(fmt.Sprintf("jobs=%d", jobs), func(t *testing.T) {
+			var mu sync.Mutex
+			running, most := 0, 0
+			err := RunParallel(jobs, 12, func(_ context.Context, i int) error {
+				mu.Lock()
+				running++
+				most = max(most, running)
+				mu.Unlock()
+				time.Sleep(time.Millisecond)
+				mu.Lock()
+				running--
+				mu.Unlock()
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("RunParallel() error = %v", err)
+			}
+			if most > jobs {
+				t.Errorf("%d calls ran at the same time, want at most %d", most, jobs)
+			}
+		})
+	}
+}
+
+func TestRunParallelError(t *testing.T) {
+	var calls atomic.Int32
+	err := RunParallel(1, 10, func(ctx context.Context, i int) error {
+		calls.Add(1)
+		if i == 3 || i == 5 {
+			return fmt.Errorf("failed at %d", i)
+		}
+		return nil
+	})
+	if err == nil || err.Error() != "failed at 3" {
+		t.Errorf("RunParallel() error = %v, want failed at 3", err)
+	}
+	if got := calls.Load(); got != 4 {
+		t.Errorf("fn was called %d times, want 4: the calls after the error were not stopped", got)
+	}
+}
+
+func TestRunParallelCancel(t *testing.T) {
+	// Calls still running when another fails see their context canceled
+	var canceled atomic.Bool
+	started := make(chan struct{})
+	err := RunParallel(2, 2, func(ctx context.Context, i int) error {
+		if i == 0 {
+			<-started
+			return fmt.Errorf("failed")
+		}
+		close(started)
+		select {
+		case <-ctx.Done():
+			canceled.Store(true)
+		case <-time.After(5 * time.Second):
+		}
+		return nil
+	})
+	if err == nil || !canceled.Load() {
+		t.Errorf("RunParallel() error = %v, canceled = %v, want an error and the context canceled", err, canceled.Load())
+	}
+}
+
+func TestRunParallelNoWork(t *testing.T) {
+	called := false
+	err := RunParallel(4, 0, func(_ context.Context, i int) error {
+		called = true
+		return nil
+	})
+	if err != nil || called {
+		t.Errorf("RunParallel() with no work = %v, called %v", err, called)
+	}
+}
@@ -0,0 +1,58 @@
+package writer
+
+import (
+	"fmt"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+)
+
+// PdfcpuWriter writes pages in-process using the pdfcpu library.
+type PdfcpuWriter struct{}
+
+func NewPdfcpuWriter() *PdfcpuWriter {
+	api.DisableConfigDir()
+	return &PdfcpuWriter{}
+}
+
+func (w *PdfcpuWriter) Name() string {
+	return WriterPdfcpu
+}
+
+func (w *PdfcpuWriter) ExtractPages(src, dst string, from, to int) error {
+	if _, err := checkRange(src, from, to); err != nil {
+		return err
+	}
+	err := api.TrimFile(src, dst, []string{pageRange(from, to)}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to write pages %s of '%s' to '%s': %v", pageRange(from, to), src, dst, err)
+	}
+	return nil
+}
+
+func (w *PdfcpuWriter) RemovePages(src string, from, to int) error {
+	totalPages, err := checkRange(src, from, to)
+	if err != nil {
+		return err
+	}
+	// pdfcpu silently writes an empty file when every page is removed
+	if from == 1 && to == totalPages {
+		return fmt.Errorf("cannot remove all %d pages of '%s': use the delete command to remove the file", totalPages, src)
+	}
+	err = api.RemovePagesFile(src, "", []string{pageRange(from, to)}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to remove pages %s from '%s': %v", pageRange(from, to), src, err)
+	}
+	return nil
+}
+
+// checkRange validates an inclusive page range against the page count of src.
+func checkRange(src string, from, to int) (int, error) {
+	totalPages, err := api.PageCountFile(src)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read '%s': %v", src, err)
+	}
+	if from < 1 || to > totalPages || from > to {
+		return 0, fmt.Errorf("invalid page range %s for '%s' (total pages: %d)", pageRange(from, to), src, totalPages)
+	}
+	return totalPages, nil
+}
@@ -0,0 +1,88 @@
+package writer
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// PdftkWriter writes pages by shelling out to pdftk.
+type PdftkWriter struct{}
+
+func (w *PdftkWriter) Name() string {
+	return WriterPdftk
+}
+
+func (w *PdftkWriter) RequiredTools() []string {
+	return []string{"pdftk"}
+}
+
+func (w *PdftkWriter) ExtractPages(src, dst string, from, to int) error {
+	return runPdftk(src, []string{pageRange(from, to)}, dst)
+}
+
+func (w *PdftkWriter) RemovePages(src string, from, to int) error {
+	totalPages, err := w.pageCount(src)
+	if err != nil {
+		return err
+	}
+	if from < 1 || to > totalPages || from > to {
+		return fmt.Errorf("invalid page range %s for '%s' (total pages: %d)", pageRange(from, to), src, totalPages)
+	}
+
+	// Construct the page ranges to keep
+	var pagesToKeep []string
+	if from > 1 {
+		pagesToKeep = append(pagesToKeep, pageRange(1, from-1))
+	}
+	if to < totalPages {
+		pagesToKeep = append(pagesToKeep, pageRange(to+1, totalPages))
+	}
+	if len(pagesToKeep) == 0 {
+		return fmt.Errorf("cannot remove all %d pages of '%s': use the delete command to remove the file", totalPages, src)
+	}
+
+	// Write the remaining pages next to the original and then replace it
+	tempFile := filepath.Join(filepath.Dir(src), "."+filepath.Base(src)+".tmp")
+	err = runPdftk(src, pagesToKeep, tempFile)
+	if err != nil {
+		os.Remove(tempFile)
+		return err
+	}
+	err = os.Rename(tempFile, src)
+	if err != nil {
+		return fmt.Errorf("failed to overwrite original PDF: %v", err)
+	}
+	return nil
+}
+
+func (w *PdftkWriter) pageCount(src string) (int, error) {
+	output, err := exec.Command("pdftk", src, "dump_data").CombinedOutput()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read '%s' using pdftk: %v: %s", src, err, strings.TrimSpace(string(output)))
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		if strings.HasPrefix(line, "NumberOfPages:") {
+			var totalPages int
+			_, err := fmt.Sscanf(line, "NumberOfPages: %d", &totalPages)
+			if err != nil {
+				return 0, fmt.Errorf("failed to parse page count: %v", err)
+			}
+			return totalPages, nil
+		}
+	}
+	return 0, fmt.Errorf("failed to find page count of '%s' in pdftk output", src)
+}
+
+// runPdftk runs "pdftk src cat ranges... output dst", reporting pdftk's own message on failure.
+func runPdftk(src string, ranges []string, dst string) error {
+	args := append([]string{src, "cat"}, ranges...)
+	args = append(args, "output", dst)
+	output, err := exec.Command("pdftk", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("pdftk failed to write pages %s of '%s' to '%s': %v: %s", strings.Join(ranges, " "), src, dst, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
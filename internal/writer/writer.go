@@ -0,0 +1,37 @@
+package writer
+
+import "fmt"
+
+const (
+	WriterPdfcpu = "pdfcpu"
+	WriterPdftk  = "pdftk"
+)
+
+// PageWriter selects pages of a PDF file and writes them out as a new document.
+type PageWriter interface {
+	// Name returns the writer name as accepted by --page-writer
+	Name() string
+	// ExtractPages writes pages from..to (inclusive) of src into dst
+	ExtractPages(src, dst string, from, to int) error
+	// RemovePages deletes pages from..to (inclusive) from src in place
+	RemovePages(src string, from, to int) error
+}
+
+// New returns the page writer with the given name.
+func New(name string) (PageWriter, error) {
+	switch name {
+	case WriterPdfcpu:
+		return NewPdfcpuWriter(), nil
+	case WriterPdftk:
+		return &PdftkWriter{}, nil
+	}
+	return nil, fmt.Errorf("unknown page writer '%s': expected '%s' or '%s'", name, WriterPdfcpu, WriterPdftk)
+}
+
+// pageRange formats an inclusive page range the way pdfcpu and pdftk expect it.
+func pageRange(from, to int) string {
+	if from == to {
+		return fmt.Sprintf("%d", from)
+	}
+	return fmt.Sprintf("%d-%d", from, to)
+}